@@ -0,0 +1,259 @@
+package vigilant
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultAdminFlushTimeout = 10 * time.Second
+)
+
+// adminStatus is the JSON document returned by the status route of the admin handler
+type adminStatus struct {
+	Name             string                   `json:"name"`
+	Endpoint         string                   `json:"endpoint"`
+	Token            string                   `json:"token"`
+	Noop             bool                     `json:"noop"`
+	Running          bool                     `json:"running"`
+	Level            LogLevel                 `json:"level"`
+	LoggerLevels     map[string]LogLevel      `json:"logger_levels"`
+	GlobalAttributes map[string]string        `json:"global_attributes"`
+	Queues           map[string]adminQueue    `json:"queues"`
+	Pipelines        map[string]adminPipeline `json:"pipelines"`
+}
+
+// adminQueue is the state of a pipeline queue
+type adminQueue struct {
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+// adminPipeline is the health of a pipeline, the durations are in milliseconds
+type adminPipeline struct {
+	Enqueued        uint64     `json:"enqueued"`
+	Dropped         uint64     `json:"dropped"`
+	Sent            uint64     `json:"sent"`
+	Failed          uint64     `json:"failed"`
+	Batches         uint64     `json:"batches"`
+	FailedBatches   uint64     `json:"failed_batches"`
+	Retries         uint64     `json:"retries"`
+	MaxBatchSize    int        `json:"max_batch_size"`
+	AvgBatchSize    float64    `json:"avg_batch_size"`
+	LastSendLatency float64    `json:"last_send_latency_ms"`
+	AvgSendLatency  float64    `json:"avg_send_latency_ms"`
+	MaxSendLatency  float64    `json:"max_send_latency_ms"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastErrorAt     *time.Time `json:"last_error_at,omitempty"`
+}
+
+// adminHandler is the http.Handler returned by AdminHandler
+type adminHandler struct {
+	getClient func() *Client
+	mux       *http.ServeMux
+}
+
+// AdminHandler returns an http.Handler to inspect and control the Vigilant instance at runtime
+// it should only be mounted on an internal admin port, since it has no authentication
+//
+// The routes are relative to where the handler is mounted, use http.StripPrefix to mount it under a path:
+//
+//	GET    /status                            the state of the pipelines, as JSON
+//	POST   /level?level=DEBUG                 sets the level, add logger=name to set the level of a named logger
+//	POST   /attributes?key=color&value=blue   sets a global attribute
+//	DELETE /attributes?key=color              removes a global attribute
+//	POST   /flush?timeout=5s                  sends the logs and metrics held by the pipelines
+func AdminHandler() http.Handler {
+	return newAdminHandler(globalClient.Load)
+}
+
+// AdminHandler returns an http.Handler to inspect and control the client at runtime, see AdminHandler
+func (c *Client) AdminHandler() http.Handler {
+	return newAdminHandler(func() *Client { return c })
+}
+
+// newAdminHandler creates the admin handler of the client returned by getClient
+func newAdminHandler(getClient func() *Client) http.Handler {
+	h := &adminHandler{
+		getClient: getClient,
+		mux:       http.NewServeMux(),
+	}
+	h.mux.HandleFunc("GET /status", h.route(h.handleStatus))
+	h.mux.HandleFunc("POST /level", h.route(h.handleLevel))
+	h.mux.HandleFunc("POST /attributes", h.route(h.handleSetAttribute))
+	h.mux.HandleFunc("DELETE /attributes", h.route(h.handleRemoveAttribute))
+	h.mux.HandleFunc("POST /flush", h.route(h.handleFlush))
+	return h
+}
+
+// ServeHTTP routes the request
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// route wraps a route with the loading of the client, answering 503 when Vigilant is not initialized
+func (h *adminHandler) route(handle func(w http.ResponseWriter, r *http.Request, client *Client)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := h.getClient()
+		if client == nil {
+			writeAdminError(w, http.StatusServiceUnavailable, "vigilant is not initialized")
+			return
+		}
+		handle(w, r, client)
+	}
+}
+
+// handleStatus writes the state of the instance
+func (h *adminHandler) handleStatus(w http.ResponseWriter, r *http.Request, client *Client) {
+	writeAdminJSON(w, http.StatusOK, client.instance.adminStatus())
+}
+
+// handleLevel sets the level of the instance or of a named logger
+func (h *adminHandler) handleLevel(w http.ResponseWriter, r *http.Request, client *Client) {
+	value := r.FormValue("level")
+	logger := r.FormValue("logger")
+	if value == "" && logger != "" {
+		client.SetLoggerLevel(logger, "")
+		writeAdminJSON(w, http.StatusOK, client.instance.adminStatus())
+		return
+	}
+	level, err := ParseLevel(value)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if logger != "" {
+		client.SetLoggerLevel(logger, level)
+	} else {
+		client.SetLevel(level)
+	}
+	writeAdminJSON(w, http.StatusOK, client.instance.adminStatus())
+}
+
+// handleSetAttribute sets a global attribute
+func (h *adminHandler) handleSetAttribute(w http.ResponseWriter, r *http.Request, client *Client) {
+	key := r.FormValue("key")
+	if key == "" {
+		writeAdminError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	client.AddGlobalAttribute(String(key, r.FormValue("value")))
+	writeAdminJSON(w, http.StatusOK, client.instance.adminStatus())
+}
+
+// handleRemoveAttribute removes a global attribute
+func (h *adminHandler) handleRemoveAttribute(w http.ResponseWriter, r *http.Request, client *Client) {
+	key := r.FormValue("key")
+	if key == "" {
+		writeAdminError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	client.RemoveGlobalAttribute(key)
+	writeAdminJSON(w, http.StatusOK, client.instance.adminStatus())
+}
+
+// handleFlush flushes the pipelines, waiting at most the timeout parameter
+func (h *adminHandler) handleFlush(w http.ResponseWriter, r *http.Request, client *Client) {
+	timeout := defaultAdminFlushTimeout
+	if value := r.FormValue("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			writeAdminError(w, http.StatusBadRequest, "timeout must be a positive duration such as 5s")
+			return
+		}
+		timeout = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	if err := client.Flush(ctx); err != nil {
+		writeAdminError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, client.instance.adminStatus())
+}
+
+// adminStatus returns the state of the instance for the admin handler
+func (a *instance) adminStatus() *adminStatus {
+	levels := a.levels.Load()
+	stats := a.stats()
+	return &adminStatus{
+		Name:             a.name,
+		Endpoint:         a.endpoint,
+		Token:            MaskToken(a.token),
+		Noop:             a.noop,
+		Running:          a.state.Load() == instanceRunning,
+		Level:            levels.level,
+		LoggerLevels:     maps.Clone(levels.loggers),
+		GlobalAttributes: a.globalAttributes(),
+		Queues: map[string]adminQueue{
+			"logs":               newAdminQueue(a.logBatcher.logQueue),
+			"metrics":            newAdminQueue(a.metricBatcher.metricQueue),
+			"counter_events":     newAdminQueue(a.metricCollector.counterEvents),
+			"gauge_events":       newAdminQueue(a.metricCollector.gaugeEvents),
+			"histogram_events":   newAdminQueue(a.metricCollector.histogramEvents),
+			"aggregated_metrics": newAdminQueue(a.metricCollector.sender.aggsQueue),
+		},
+		Pipelines: map[string]adminPipeline{
+			PipelineLogs:      newAdminPipeline(stats.Logs),
+			PipelineMetrics:   newAdminPipeline(stats.Metrics),
+			PipelineCollector: newAdminPipeline(stats.Collector),
+		},
+	}
+}
+
+// newAdminQueue returns the state of a queue
+func newAdminQueue[T any](q *queue[T]) adminQueue {
+	return adminQueue{
+		Depth:    q.depth(),
+		Capacity: cap(q.items),
+		Dropped:  q.droppedCount(),
+	}
+}
+
+// newAdminPipeline returns the health of a pipeline
+func newAdminPipeline(stats PipelineStats) adminPipeline {
+	pipeline := adminPipeline{
+		Enqueued:        stats.Enqueued,
+		Dropped:         stats.Dropped,
+		Sent:            stats.Sent,
+		Failed:          stats.Failed,
+		Batches:         stats.Batches,
+		FailedBatches:   stats.FailedBatches,
+		Retries:         stats.Retries,
+		MaxBatchSize:    stats.MaxBatchSize,
+		AvgBatchSize:    stats.AvgBatchSize,
+		LastSendLatency: milliseconds(stats.LastSendLatency),
+		AvgSendLatency:  milliseconds(stats.AvgSendLatency),
+		MaxSendLatency:  milliseconds(stats.MaxSendLatency),
+	}
+	if stats.LastError != nil {
+		pipeline.LastError = stats.LastError.Error()
+		pipeline.LastErrorAt = &stats.LastErrorAt
+	}
+	return pipeline
+}
+
+// milliseconds returns the duration in milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// writeAdminJSON writes a JSON response
+func writeAdminJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(body)
+}
+
+// writeAdminError writes a JSON error response
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	writeAdminJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,138 @@
+package vigilant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveAdmin sends a request to the admin handler and decodes the JSON response into body, resetting a status first
+func serveAdmin(t *testing.T, handler http.Handler, method, target string, body any) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	if status, ok := body.(*adminStatus); ok {
+		*status = adminStatus{}
+	}
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	if body != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), body); err != nil {
+			t.Fatalf("%s %s: %v: %s", method, target, err, recorder.Body.String())
+		}
+	}
+	return recorder.Code
+}
+
+func TestAdminHandlerStatus(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).WithToken("tk_0123456789abcdef").Build())
+	defer client.Shutdown()
+
+	var status adminStatus
+	if code := serveAdmin(t, client.AdminHandler(), http.MethodGet, "/status", &status); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if status.Name != "test" || !status.Running || status.Level != LEVEL_TRACE {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if status.Token != "tk_0***********cdef" {
+		t.Errorf("expected a masked token, got %q", status.Token)
+	}
+	if _, ok := status.Queues["logs"]; !ok {
+		t.Errorf("expected the log queue, got %v", status.Queues)
+	}
+	if _, ok := status.Pipelines[PipelineCollector]; !ok {
+		t.Errorf("expected the collector pipeline, got %v", status.Pipelines)
+	}
+}
+
+func TestAdminHandlerLevel(t *testing.T) {
+	client := NewClient(NewConfigBuilder().WithNoop(true).Build())
+	defer client.Shutdown()
+	handler := client.AdminHandler()
+
+	var status adminStatus
+	if code := serveAdmin(t, handler, http.MethodPost, "/level?level=warn", &status); code != http.StatusOK || status.Level != LEVEL_WARN {
+		t.Fatalf("expected the level to be WARNING, got %d %q", code, status.Level)
+	}
+	if code := serveAdmin(t, handler, http.MethodPost, "/level?level=debug&logger=db", &status); code != http.StatusOK || status.LoggerLevels["db"] != LEVEL_DEBUG {
+		t.Fatalf("expected the level of db to be DEBUG, got %d %v", code, status.LoggerLevels)
+	}
+	if code := serveAdmin(t, handler, http.MethodPost, "/level?logger=db", &status); code != http.StatusOK || len(status.LoggerLevels) != 0 {
+		t.Fatalf("expected the level of db to be removed, got %d %v", code, status.LoggerLevels)
+	}
+
+	var failure map[string]string
+	if code := serveAdmin(t, handler, http.MethodPost, "/level?level=VERBOSE", &failure); code != http.StatusBadRequest || failure["error"] == "" {
+		t.Fatalf("expected 400 with an error, got %d %v", code, failure)
+	}
+	if level := client.Level(); level != LEVEL_WARN {
+		t.Fatalf("expected the level to be kept, got %q", level)
+	}
+}
+
+func TestAdminHandlerAttributes(t *testing.T) {
+	client := NewClient(NewConfigBuilder().WithNoop(true).Build())
+	defer client.Shutdown()
+	handler := client.AdminHandler()
+
+	var status adminStatus
+	if code := serveAdmin(t, handler, http.MethodPost, "/attributes?key=color&value=blue", &status); code != http.StatusOK || status.GlobalAttributes["color"] != "blue" {
+		t.Fatalf("expected color=blue, got %d %v", code, status.GlobalAttributes)
+	}
+	if code := serveAdmin(t, handler, http.MethodDelete, "/attributes?key=color", &status); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if _, ok := status.GlobalAttributes["color"]; ok {
+		t.Fatalf("expected color to be removed, got %v", status.GlobalAttributes)
+	}
+	if code := serveAdmin(t, handler, http.MethodPost, "/attributes?value=blue", nil); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a key, got %d", code)
+	}
+}
+
+func TestAdminHandlerFlush(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).Build())
+	defer client.Shutdown()
+	handler := client.AdminHandler()
+
+	client.LogInfo("flushed")
+	var status adminStatus
+	if code := serveAdmin(t, handler, http.MethodPost, "/flush?timeout=5s", &status); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if count := server.logCount(); count != 1 {
+		t.Fatalf("expected the log to be sent by the flush, got %d", count)
+	}
+	if code := serveAdmin(t, handler, http.MethodPost, "/flush?timeout=soon", nil); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid timeout, got %d", code)
+	}
+}
+
+func TestAdminHandlerNotInitialized(t *testing.T) {
+	var failure map[string]string
+	if code := serveAdmin(t, AdminHandler(), http.MethodGet, "/status", &failure); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", code)
+	}
+	if !strings.Contains(failure["error"], "not initialized") {
+		t.Fatalf("expected a not initialized error, got %v", failure)
+	}
+	if code := serveAdmin(t, AdminHandler(), http.MethodGet, "/level", nil); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", code)
+	}
+}
+
+func TestMaskToken(t *testing.T) {
+	for token, want := range map[string]string{
+		"":                    "",
+		"tk_short":            "********",
+		"tk_1234567890":       "tk_1*****7890",
+		"tk_0123456789abcdef": "tk_0***********cdef",
+	} {
+		if got := MaskToken(token); got != want {
+			t.Errorf("MaskToken(%q): expected %q, got %q", token, want, got)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package vigilant
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Client is a Vigilant client built from a VigilantConfig
+// each client owns its own log and metric pipelines, so several differently-configured
+// clients can run side by side in the same program
+type Client struct {
+	instance *instance
+}
+
+// NewClient creates a new Vigilant client from the given config and starts it
+// The client should be shut down with Shutdown when it is no longer needed
+// a nil config is reported and the client is a noop client
+func NewClient(config *VigilantConfig) *Client {
+	if config == nil {
+		packageDiagnostics().reportError(errNilConfig)
+		config = NewNoopConfig()
+		config.Passthrough = false
+	}
+	instance := newVigilant(config)
+	instance.start()
+	return &Client{instance: instance}
+}
+
+// Shutdown shuts down the client, sending any logs and metrics it still holds
+func (c *Client) Shutdown() error {
+	return c.instance.shutdown(context.Background())
+}
+
+// ShutdownContext shuts down the client like Shutdown, giving up on the remaining sends when the context is done
+// the returned error joins the sends that failed or were cancelled
+func (c *Client) ShutdownContext(ctx context.Context) error {
+	return c.instance.shutdown(ctx)
+}
+
+// Flush sends the logs and metrics the client holds without shutting it down
+// it returns when everything is sent or the context is done, joining the sends that failed
+func (c *Client) Flush(ctx context.Context) error {
+	return c.instance.flush(ctx)
+}
+
+// Dropped returns the number of items each pipeline dropped because its queue was full
+func (c *Client) Dropped() DroppedCounts {
+	return c.instance.droppedCounts()
+}
+
+// Stats returns the health of the pipelines of the client
+func (c *Client) Stats() StatsSnapshot {
+	return c.instance.stats()
+}
+
+// SetGlobalAttributes replaces the global attributes of the client
+// the global attributes are added to every log and metric, the service attribute of the config name is kept
+func (c *Client) SetGlobalAttributes(attributes ...Attribute) {
+	c.instance.replaceGlobalAttributes(attributesToMap(attributes...))
+}
+
+// AddGlobalAttribute adds a global attribute to the client, replacing the attribute with the same key
+func (c *Client) AddGlobalAttribute(attribute Attribute) {
+	c.instance.setGlobalAttribute(attribute.Key, attribute.Value)
+}
+
+// RemoveGlobalAttribute removes a global attribute from the client
+func (c *Client) RemoveGlobalAttribute(key string) {
+	c.instance.removeGlobalAttribute(key)
+}
+
+// GlobalAttributes returns a copy of the global attributes of the client
+func (c *Client) GlobalAttributes() map[string]string {
+	return c.instance.globalAttributes()
+}
+
+// SetLevel changes the minimum level of the logs sent by the client
+// the level is parsed with ParseLevel, an invalid level is reported to the error handler and ignored
+func (c *Client) SetLevel(level LogLevel) {
+	parsed, ok := c.instance.parseRuntimeLevel("Level", level)
+	if !ok {
+		return
+	}
+	c.instance.updateLevels(func(state *levelState) {
+		state.level = parsed
+	})
+}
+
+// Level returns the minimum level of the logs sent by the client
+func (c *Client) Level() LogLevel {
+	return c.instance.levels.Load().level
+}
+
+// SetLoggerLevel changes the minimum level of the logs sent by the loggers with the given name
+// it takes precedence over the level set with SetLevel, an empty level removes the override
+// and an invalid level is reported to the error handler and ignored
+func (c *Client) SetLoggerLevel(name string, level LogLevel) {
+	if level == "" {
+		c.instance.updateLevels(func(state *levelState) {
+			delete(state.loggers, name)
+		})
+		return
+	}
+	parsed, ok := c.instance.parseRuntimeLevel(loggerLevelField(name), level)
+	if !ok {
+		return
+	}
+	c.instance.updateLevels(func(state *levelState) {
+		state.loggers[name] = parsed
+	})
+}
+
+// SetLoggerLevels replaces every per-logger level of the client
+// the levels are kept as they are when one of them is invalid, every invalid level is reported to the error handler
+func (c *Client) SetLoggerLevels(levels map[string]LogLevel) {
+	parsed := make(map[string]LogLevel, len(levels))
+	valid := true
+	for _, name := range slices.Sorted(maps.Keys(levels)) {
+		level, ok := c.instance.parseRuntimeLevel(loggerLevelField(name), levels[name])
+		parsed[name] = level
+		valid = valid && ok
+	}
+	if !valid {
+		return
+	}
+	c.instance.updateLevels(func(state *levelState) {
+		state.loggers = parsed
+	})
+}
+
+// ----------------------- //
+// --- General Logging --- //
+// ----------------------- //
+
+// Log logs a message at the given level
+func (c *Client) Log(level LogLevel, message string) {
+	c.log(level, message, nil)
+}
+
+// LogError logs an error message
+func (c *Client) LogError(message string) {
+	c.log(LEVEL_ERROR, message, nil)
+}
+
+// LogWarn logs a warning message
+func (c *Client) LogWarn(message string) {
+	c.log(LEVEL_WARN, message, nil)
+}
+
+// LogInfo logs an info message
+func (c *Client) LogInfo(message string) {
+	c.log(LEVEL_INFO, message, nil)
+}
+
+// LogDebug logs a debug message
+func (c *Client) LogDebug(message string) {
+	c.log(LEVEL_DEBUG, message, nil)
+}
+
+// LogTrace logs a trace message
+func (c *Client) LogTrace(message string) {
+	c.log(LEVEL_TRACE, message, nil)
+}
+
+// ------------------------- //
+// --- Formatted Logging --- //
+// ------------------------- //
+
+// LogErrorf logs an error message with a formatted message
+func (c *Client) LogErrorf(template string, args ...any) {
+	c.log(LEVEL_ERROR, fmt.Sprintf(template, args...), nil)
+}
+
+// LogWarnf logs a warning message with a formatted message
+func (c *Client) LogWarnf(template string, args ...any) {
+	c.log(LEVEL_WARN, fmt.Sprintf(template, args...), nil)
+}
+
+// LogInfof logs an info message with a formatted message
+func (c *Client) LogInfof(template string, args ...any) {
+	c.log(LEVEL_INFO, fmt.Sprintf(template, args...), nil)
+}
+
+// LogDebugf logs a debug message with a formatted message
+func (c *Client) LogDebugf(template string, args ...any) {
+	c.log(LEVEL_DEBUG, fmt.Sprintf(template, args...), nil)
+}
+
+// LogTracef logs a trace message with a formatted message
+func (c *Client) LogTracef(template string, args ...any) {
+	c.log(LEVEL_TRACE, fmt.Sprintf(template, args...), nil)
+}
+
+// ------------------------------- //
+// --- Typed Attribute Logging --- //
+// ------------------------------- //
+
+// LogErrort logs an error message with typed attributes
+func (c *Client) LogErrort(message string, attributes ...Attribute) {
+	c.log(LEVEL_ERROR, message, attributesToMap(attributes...))
+}
+
+// LogWarnt logs a warning message with typed attributes
+func (c *Client) LogWarnt(message string, attributes ...Attribute) {
+	c.log(LEVEL_WARN, message, attributesToMap(attributes...))
+}
+
+// LogInfot logs an info message with typed attributes
+func (c *Client) LogInfot(message string, attributes ...Attribute) {
+	c.log(LEVEL_INFO, message, attributesToMap(attributes...))
+}
+
+// LogDebugt logs a debug message with typed attributes
+func (c *Client) LogDebugt(message string, attributes ...Attribute) {
+	c.log(LEVEL_DEBUG, message, attributesToMap(attributes...))
+}
+
+// LogTracet logs a trace message with typed attributes
+func (c *Client) LogTracet(message string, attributes ...Attribute) {
+	c.log(LEVEL_TRACE, message, attributesToMap(attributes...))
+}
+
+// -------------------------------- //
+// --- Free-form Attribute Logs --- //
+// -------------------------------- //
+
+// LogErrorw logs an error message with key-value attributes
+func (c *Client) LogErrorw(message string, keyVals ...any) {
+	c.logw(LEVEL_ERROR, message, keyVals...)
+}
+
+// LogWarnw logs a warning message with key-value attributes
+func (c *Client) LogWarnw(message string, keyVals ...any) {
+	c.logw(LEVEL_WARN, message, keyVals...)
+}
+
+// LogInfow logs an info message with key-value attributes
+func (c *Client) LogInfow(message string, keyVals ...any) {
+	c.logw(LEVEL_INFO, message, keyVals...)
+}
+
+// LogDebugw logs a debug message with key-value attributes
+func (c *Client) LogDebugw(message string, keyVals ...any) {
+	c.logw(LEVEL_DEBUG, message, keyVals...)
+}
+
+// ----------------------- //
+// --- Context Logging --- //
+// ----------------------- //
+
+// LogCtx logs a message at the given level with the context attributes
+func (c *Client) LogCtx(ctx context.Context, level LogLevel, message string, attributes ...Attribute) {
+	c.log(level, message, contextAttributes(ctx, attributes...))
+}
+
+// LogErrorCtx logs an error message with the context attributes and typed attributes
+func (c *Client) LogErrorCtx(ctx context.Context, message string, attributes ...Attribute) {
+	c.log(LEVEL_ERROR, message, contextAttributes(ctx, attributes...))
+}
+
+// LogWarnCtx logs a warning message with the context attributes and typed attributes
+func (c *Client) LogWarnCtx(ctx context.Context, message string, attributes ...Attribute) {
+	c.log(LEVEL_WARN, message, contextAttributes(ctx, attributes...))
+}
+
+// LogInfoCtx logs an info message with the context attributes and typed attributes
+func (c *Client) LogInfoCtx(ctx context.Context, message string, attributes ...Attribute) {
+	c.log(LEVEL_INFO, message, contextAttributes(ctx, attributes...))
+}
+
+// LogDebugCtx logs a debug message with the context attributes and typed attributes
+func (c *Client) LogDebugCtx(ctx context.Context, message string, attributes ...Attribute) {
+	c.log(LEVEL_DEBUG, message, contextAttributes(ctx, attributes...))
+}
+
+// LogTraceCtx logs a trace message with the context attributes and typed attributes
+func (c *Client) LogTraceCtx(ctx context.Context, message string, attributes ...Attribute) {
+	c.log(LEVEL_TRACE, message, contextAttributes(ctx, attributes...))
+}
+
+// --------------- //
+// --- Metrics --- //
+// --------------- //
+
+// MetricEvent captures a metric
+func (c *Client) MetricEvent(name string, value float64, tags ...MetricTag) {
+	metric := createMetricMessage(name, value, tags...)
+	if metric == nil {
+		return
+	}
+
+	c.instance.captureMetric(metric)
+}
+
+// MetricEventCtx captures a metric tagged with the context attributes
+func (c *Client) MetricEventCtx(ctx context.Context, name string, value float64, tags ...MetricTag) {
+	c.MetricEvent(name, value, slices.Concat(tags, contextTags(ctx))...)
+}
+
+// DEPRECATED: Use MetricEvent instead
+// MetricCounter captures a counter metric
+func (c *Client) MetricCounter(name string, value float64, tags ...MetricTag) {
+	if value < 0 {
+		return
+	}
+
+	counter := createCounterEvent(name, value, tags...)
+	if counter == nil {
+		return
+	}
+
+	c.instance.captureCounter(counter)
+}
+
+// DEPRECATED: Use MetricEvent instead
+// MetricGauge captures a gauge metric
+func (c *Client) MetricGauge(name string, value float64, mode GaugeMode, tags ...MetricTag) {
+	if value < 0 {
+		return
+	}
+
+	gauge := createGaugeEvent(name, value, mode, tags...)
+	if gauge == nil {
+		return
+	}
+
+	c.instance.captureGauge(gauge)
+}
+
+// DEPRECATED: Use MetricEvent instead
+// MetricHistogram captures a histogram metric
+func (c *Client) MetricHistogram(name string, value float64, tags ...MetricTag) {
+	if value < 0 {
+		return
+	}
+
+	histogram := createHistogramEvent(name, value, tags...)
+	if histogram == nil {
+		return
+	}
+
+	c.instance.captureHistogram(histogram)
+}
+
+// log creates a log message and hands it to the instance
+func (c *Client) log(level LogLevel, message string, attrs map[string]string) {
+	c.logNamed("", level, message, attrs)
+}
+
+// logNamed logs the message if the level is enabled for the named logger
+func (c *Client) logNamed(name string, level LogLevel, message string, attrs map[string]string) {
+	if !c.instance.levelEnabled(level, name) {
+		return
+	}
+
+	log := createLogMessage(level, message, attrs)
+	if log == nil {
+		return
+	}
+
+	c.instance.captureLog(log)
+}
+
+// logw converts the key-value pairs into attributes and logs the message
+func (c *Client) logw(level LogLevel, message string, keyVals ...any) {
+	attrs, err := keyValsToMap(keyVals...)
+	if err != nil {
+		c.instance.diag.reportError(&CaptureError{Pipeline: PipelineLogs, Err: err})
+		return
+	}
+
+	c.log(level, message, attrs)
+}
@@ -0,0 +1,111 @@
+package vigilant
+
+import (
+	"slices"
+	"testing"
+)
+
+// logBodies returns the bodies of the logs in the accepted batches, sorted
+func (s *testServer) logBodies() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var bodies []string
+	for _, batch := range s.batches {
+		for _, log := range batch.Logs {
+			bodies = append(bodies, log.Body)
+		}
+	}
+	slices.Sort(bodies)
+	return bodies
+}
+
+// metricNames returns the names of the metric events and counters in the accepted batches, sorted
+func (s *testServer) metricNames() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var names []string
+	for _, batch := range s.batches {
+		for _, metric := range batch.Metrics {
+			names = append(names, metric.Name)
+		}
+		for _, counter := range batch.MetricsCounters {
+			names = append(names, counter.MetricName)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestPackageFunctionsUseDefaultClient(t *testing.T) {
+	server := newTestServer(t, nil)
+	other := newTestServer(t, nil)
+	if err := InitE(testConfig(server).Build()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+	client := NewClient(testConfig(other).Build())
+
+	LogInfo("info")
+	LogWarnf("warn %d", 1)
+	LogErrort("error", String("code", "E1"))
+	LogDebugw("debug", "key", "value")
+	MetricEvent("event", 1)
+	MetricCounter("counter", 1)
+	client.LogInfo("client")
+
+	if stats := globalClient.Load().Stats(); stats.Logs.Enqueued != 4 {
+		t.Errorf("expected the default client to enqueue the 4 package logs, got %d", stats.Logs.Enqueued)
+	}
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	if bodies := server.logBodies(); !slices.Equal(bodies, []string{"debug", "error", "info", "warn 1"}) {
+		t.Errorf("expected the package logs on the server of the default client, got %v", bodies)
+	}
+	if names := server.metricNames(); !slices.Equal(names, []string{"counter", "event"}) {
+		t.Errorf("expected the package metrics on the server of the default client, got %v", names)
+	}
+	if bodies := other.logBodies(); !slices.Equal(bodies, []string{"client"}) {
+		t.Errorf("expected only the log of the other client on its server, got %v", bodies)
+	}
+}
+
+func TestClientsAreIndependent(t *testing.T) {
+	first := newTestServer(t, nil)
+	second := newTestServer(t, nil)
+	a := NewClient(testConfig(first).WithName("a").WithLevel(LEVEL_INFO).Build())
+	b := NewClient(testConfig(second).WithName("b").WithLevel(LEVEL_DEBUG).Build())
+
+	a.LogDebug("a debug")
+	a.LogInfo("a info")
+	b.LogDebug("b debug")
+	b.MetricEvent("b event", 1)
+
+	if err := a.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	b.LogInfo("b after a shut down")
+	if err := b.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	if bodies := first.logBodies(); !slices.Equal(bodies, []string{"a info"}) {
+		t.Errorf("expected the first server to get the info log of a, got %v", bodies)
+	}
+	if bodies := second.logBodies(); !slices.Equal(bodies, []string{"b after a shut down", "b debug"}) {
+		t.Errorf("expected the second server to get the logs of b, got %v", bodies)
+	}
+	if names := first.metricNames(); len(names) != 0 {
+		t.Errorf("expected no metrics on the first server, got %v", names)
+	}
+
+	first.mux.Lock()
+	defer first.mux.Unlock()
+	if service := first.batches[0].Logs[0].Attributes["service"]; service != "a" {
+		t.Errorf("expected the service attribute of a, got %q", service)
+	}
+}
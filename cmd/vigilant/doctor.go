@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+const (
+	defaultDoctorTimeout = 5 * time.Second
+	certExpiryWarning    = 14 * 24 * time.Hour
+)
+
+const doctorUsage = `Usage: vigilant doctor [flags]
+
+Checks the configuration and the connection to Vigilant step by step (DNS, TCP, TLS, HTTP),
+sends a test log and reports what is misconfigured.
+The exit code is 1 when a check failed.
+
+Flags:
+`
+
+// checkStatus is the outcome of a doctor check
+type checkStatus string
+
+const (
+	checkOK   checkStatus = "ok"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+	checkSkip checkStatus = "skip"
+)
+
+// doctor runs the checks and prints the report
+type doctor struct {
+	config  *vigilant.VigilantConfig
+	timeout time.Duration
+	out     io.Writer
+
+	host   string
+	port   string
+	failed bool
+}
+
+// runDoctor runs the doctor command and returns the exit code
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), doctorUsage)
+		fs.PrintDefaults()
+	}
+
+	var connection connectionFlags
+	connection.register(fs)
+	timeout := fs.Duration("timeout", defaultDoctorTimeout, "timeout of every check")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	d := &doctor{
+		config:  connection.builder().WithRetry(vigilant.RetryConfig{MaxAttempts: 1}).Build(),
+		timeout: *timeout,
+		out:     os.Stdout,
+	}
+	d.run()
+
+	if d.failed {
+		fmt.Fprintln(d.out, "\nsome checks failed, see the fixes above")
+		return 1
+	}
+	fmt.Fprintln(d.out, "\nall checks passed")
+	return 0
+}
+
+// run runs the checks in order, skipping the checks that depend on a failed one
+func (d *doctor) run() {
+	fmt.Fprintf(d.out, "endpoint  %s\n", d.config.EndpointURL())
+	fmt.Fprintf(d.out, "token     %s\n", vigilant.MaskToken(d.config.Token))
+	fmt.Fprintf(d.out, "name      %s\n\n", d.config.Name)
+
+	checks := []struct {
+		name string
+		run  func() bool
+	}{
+		{"config", d.checkConfig},
+		{"dns", d.checkDNS},
+		{"tcp", d.checkTCP},
+		{"tls", d.checkTLS},
+		{"http", d.checkHTTP},
+	}
+	for i, check := range checks {
+		if !check.run() {
+			for _, skipped := range checks[i+1:] {
+				d.report(checkSkip, skipped.name, "not run because the "+check.name+" check failed")
+			}
+			return
+		}
+	}
+}
+
+// checkConfig checks the token and endpoint settings
+func (d *doctor) checkConfig() bool {
+	if d.config.Token == "" {
+		d.report(checkFail, "config", "no token is set", "pass --token or set VIGILANT_TOKEN")
+		return false
+	}
+
+	if err := d.config.Validate(); err != nil {
+		d.report(checkFail, "config", strings.ReplaceAll(err.Error(), "\n", "; "),
+			"set valid values with the flags or the VIGILANT_* environment variables, the endpoint is a host with an optional port")
+		return false
+	}
+
+	endpoint, err := url.Parse(d.config.EndpointURL())
+	if err != nil {
+		d.report(checkFail, "config", err.Error())
+		return false
+	}
+	d.host = endpoint.Hostname()
+	d.port = endpoint.Port()
+	if d.port == "" {
+		d.port = "443"
+		if d.config.Insecure {
+			d.port = "80"
+		}
+	}
+
+	if d.config.Insecure && !isLocalHost(d.host) {
+		d.report(checkWarn, "config", "--insecure sends the token unencrypted over the network",
+			"remove --insecure unless the endpoint only serves plain HTTP")
+		return true
+	}
+	d.report(checkOK, "config", "token and endpoint are set")
+	return true
+}
+
+// checkDNS checks that the host of the endpoint resolves
+func (d *doctor) checkDNS() bool {
+	if net.ParseIP(d.host) != nil {
+		d.report(checkOK, "dns", d.host+" is an IP address")
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, d.host)
+	if err != nil {
+		d.report(checkFail, "dns", fmt.Sprintf("%s does not resolve: %v", d.host, err),
+			"check the spelling of the endpoint and the DNS settings of this machine")
+		return false
+	}
+	d.report(checkOK, "dns", fmt.Sprintf("%s resolves to %s", d.host, strings.Join(addrs, ", ")))
+	return true
+}
+
+// checkTCP checks that a connection to the endpoint can be opened
+func (d *doctor) checkTCP() bool {
+	address := net.JoinHostPort(d.host, d.port)
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, d.timeout)
+	if err != nil {
+		d.report(checkFail, "tcp", fmt.Sprintf("cannot connect to %s: %v", address, err),
+			"check that the port is right and that no firewall or proxy blocks outgoing connections")
+		return false
+	}
+	conn.Close()
+	d.report(checkOK, "tcp", fmt.Sprintf("connected to %s in %s", address, time.Since(start).Round(time.Millisecond)))
+	return true
+}
+
+// checkTLS checks the TLS handshake and the certificate of the endpoint
+func (d *doctor) checkTLS() bool {
+	if d.config.Insecure {
+		d.report(checkSkip, "tls", "--insecure uses plain HTTP")
+		return true
+	}
+
+	dialer := &net.Dialer{Timeout: d.timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(d.host, d.port), &tls.Config{ServerName: d.host})
+	if err != nil {
+		d.report(checkFail, "tls", "handshake failed: "+err.Error(), tlsFix(err))
+		return false
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	cert := state.PeerCertificates[0]
+	detail := fmt.Sprintf("%s, certificate for %s valid until %s",
+		tls.VersionName(state.Version), cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+	if time.Until(cert.NotAfter) < certExpiryWarning {
+		d.report(checkWarn, "tls", detail, "the certificate expires soon")
+		return true
+	}
+	d.report(checkOK, "tls", detail)
+	return true
+}
+
+// checkHTTP sends a test log and interprets the response
+func (d *doctor) checkHTTP() bool {
+	exporter := vigilant.NewVigilantExporter(d.config)
+	defer exporter.Shutdown(context.Background())
+
+	log := &vigilant.LogMessage{
+		Timestamp:  time.Now(),
+		Body:       "vigilant doctor test log",
+		Level:      vigilant.LEVEL_INFO,
+		Attributes: map[string]string{"service": d.config.Name, "source": "vigilant-doctor"},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	start := time.Now()
+	err := exporter.ExportLogs(ctx, []*vigilant.LogMessage{log})
+	if err == nil {
+		d.report(checkOK, "http", fmt.Sprintf("test log accepted in %s", time.Since(start).Round(time.Millisecond)))
+		return true
+	}
+
+	var statusErr *vigilant.StatusError
+	if !errors.As(err, &statusErr) {
+		fix := "check that the endpoint is a Vigilant ingress server"
+		if d.config.Insecure && strings.Contains(err.Error(), "malformed HTTP response") {
+			fix = "the server expects HTTPS, remove --insecure"
+		}
+		d.report(checkFail, "http", "request failed: "+err.Error(), fix)
+		return false
+	}
+
+	detail := statusErr.Error()
+	switch code := statusErr.StatusCode; {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		d.report(checkFail, "http", detail, "the token was rejected, check that it is the token of your project")
+	case code == http.StatusNotFound || code == http.StatusMethodNotAllowed:
+		d.report(checkFail, "http", detail, "the endpoint does not serve the Vigilant API, check --endpoint")
+	case code == http.StatusTooManyRequests:
+		d.report(checkWarn, "http", detail, "the token is accepted but rate limited, logs are retried later")
+		return true
+	case code >= 500:
+		d.report(checkFail, "http", detail, "the server failed, try again later")
+	default:
+		d.report(checkFail, "http", detail, "the server rejected the test log")
+	}
+	return false
+}
+
+// report prints the outcome of a check and the fixes to apply
+func (d *doctor) report(status checkStatus, check string, detail string, fixes ...string) {
+	if status == checkFail {
+		d.failed = true
+	}
+	fmt.Fprintf(d.out, "[%-4s] %-6s %s\n", status, check, detail)
+	for _, fix := range fixes {
+		fmt.Fprintf(d.out, "              fix: %s\n", fix)
+	}
+}
+
+// tlsFix returns the fix of a failed TLS handshake
+func tlsFix(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "the certificate is not trusted, a proxy may intercept TLS or the CA certificates of this machine are missing"
+	case errors.As(err, &hostname):
+		return "the certificate does not match the endpoint, check --endpoint"
+	case errors.As(err, &invalid):
+		return "the certificate is invalid or expired, check the clock of this machine"
+	case strings.Contains(err.Error(), "first record does not look like a TLS handshake"):
+		return "the server only speaks plain HTTP, use --insecure"
+	default:
+		return "check that the endpoint serves HTTPS on this port"
+	}
+}
+
+// isLocalHost reports whether the host is the local machine
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
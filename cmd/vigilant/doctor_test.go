@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+// runDoctorOutput runs the doctor command and returns its exit code and what it printed
+func runDoctorOutput(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	stdout := os.Stdout
+	os.Stdout = out
+	code := runDoctor(args)
+	os.Stdout = stdout
+
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	printed, err := io.ReadAll(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(printed)
+}
+
+// expectLines fails the test when the output misses one of the lines
+func expectLines(t *testing.T, output string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected %q in the output:\n%s", line, output)
+		}
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	t.Setenv(vigilant.EnvToken, "")
+	t.Setenv(vigilant.EnvEndpoint, "")
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	unreachable := strings.TrimPrefix(closed.URL, "http://")
+	closed.Close()
+
+	t.Run("passing", func(t *testing.T) {
+		status = http.StatusOK
+		code, output := runDoctorOutput(t, "--token", "tk_test", "--endpoint", endpoint, "--insecure")
+		if code != 0 {
+			t.Errorf("expected exit code 0, got %d", code)
+		}
+		expectLines(t, output, "[ok  ] config", "[ok  ] tcp", "[skip] tls", "[ok  ] http", "all checks passed")
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		code, output := runDoctorOutput(t, "--endpoint", endpoint, "--insecure")
+		if code != 1 {
+			t.Errorf("expected exit code 1, got %d", code)
+		}
+		expectLines(t, output, "[fail] config", "token is the placeholder of the default config", "[skip] http", "some checks failed")
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		code, output := runDoctorOutput(t, "--token", "tk_test", "--endpoint", unreachable, "--insecure", "--timeout", "1s")
+		if code != 1 {
+			t.Errorf("expected exit code 1, got %d", code)
+		}
+		expectLines(t, output, "[ok  ] dns", "[fail] tcp", "cannot connect to "+unreachable, "no firewall or proxy",
+			"[skip] http   not run because the tcp check failed", "some checks failed")
+	})
+
+	t.Run("bad token", func(t *testing.T) {
+		status = http.StatusUnauthorized
+		code, output := runDoctorOutput(t, "--token", "tk_wrong", "--endpoint", endpoint, "--insecure")
+		if code != 1 {
+			t.Errorf("expected exit code 1, got %d", code)
+		}
+		expectLines(t, output, "[ok  ] tcp", "[fail] http", "the token was rejected", "some checks failed")
+		if strings.Contains(output, "tk_wrong") {
+			t.Errorf("expected the token to be masked:\n%s", output)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		status = http.StatusTooManyRequests
+		code, output := runDoctorOutput(t, "--token", "tk_test", "--endpoint", endpoint, "--insecure")
+		if code != 0 {
+			t.Errorf("expected a warning to keep exit code 0, got %d", code)
+		}
+		expectLines(t, output, "[warn] http", "rate limited", "all checks passed")
+	})
+
+	t.Run("bad flag", func(t *testing.T) {
+		if code, _ := runDoctorOutput(t, "--unknown"); code != 2 {
+			t.Errorf("expected exit code 2, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Command vigilant is the command line tool of the Vigilant Go SDK
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+const defaultName = "vigilant-cli"
+
+const usage = `Usage: vigilant <command> [flags]
+
+Commands:
+  pipe      send the lines read from stdin to Vigilant as logs
+  run       run a command and send its output to Vigilant as logs
+  metric    send metrics to Vigilant and wait until they are delivered
+  replay    upload NDJSON archives and spool segments of logs and metrics to Vigilant
+  doctor    check the connection to Vigilant and report what is misconfigured
+
+Run "vigilant <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "pipe":
+		os.Exit(runPipe(os.Args[2:]))
+	case "run":
+		os.Exit(runRun(os.Args[2:]))
+	case "doctor":
+		os.Exit(runDoctor(os.Args[2:]))
+	case "metric":
+		os.Exit(runMetric(os.Args[2:]))
+	case "replay":
+		os.Exit(runReplay(os.Args[2:]))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// connectionFlags are the flags selecting the Vigilant server and token
+type connectionFlags struct {
+	name     string
+	token    string
+	endpoint string
+	insecure bool
+}
+
+// register registers the connection flags on the flag set
+func (c *connectionFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.name, "name", "", "name of the service sending the data, defaults to $VIGILANT_NAME or "+defaultName)
+	fs.StringVar(&c.token, "token", "", "Vigilant API token, defaults to $VIGILANT_TOKEN")
+	fs.StringVar(&c.endpoint, "endpoint", "", "Vigilant server endpoint, defaults to $VIGILANT_ENDPOINT or ingress.vigilant.run")
+	fs.BoolVar(&c.insecure, "insecure", false, "use HTTP instead of HTTPS, defaults to $VIGILANT_INSECURE")
+}
+
+// builder creates the config builder of the connection flags
+// the settings that are not given as flags are read from the VIGILANT_* environment variables
+func (c *connectionFlags) builder() *vigilant.VigilantConfigBuilder {
+	builder := vigilant.NewConfigBuilder().
+		WithEnv().
+		WithInternalLogger(log.New(os.Stderr, "vigilant: ", 0))
+	if c.name != "" {
+		builder.WithName(c.name)
+	} else if os.Getenv(vigilant.EnvName) == "" {
+		builder.WithName(defaultName)
+	}
+	if c.token != "" {
+		builder.WithToken(c.token)
+	}
+	if c.endpoint != "" {
+		builder.WithEndpoint(c.endpoint)
+	}
+	if c.insecure {
+		builder.WithInsecure(true)
+	}
+	return builder
+}
+
+// config builds the VigilantConfig of the connection flags
+func (c *connectionFlags) config() *vigilant.VigilantConfig {
+	return c.builder().Build()
+}
+
+// attributeFlags is a repeatable flag of key=value attributes
+type attributeFlags []vigilant.Attribute
+
+// String returns the attributes as key=value pairs
+func (a *attributeFlags) String() string {
+	pairs := make([]string, 0, len(*a))
+	for _, attribute := range *a {
+		pairs = append(pairs, attribute.Key+"="+attribute.Value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set parses a key=value attribute
+func (a *attributeFlags) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if !found || key == "" {
+		return fmt.Errorf("attribute %q is not key=value", value)
+	}
+	*a = append(*a, vigilant.String(key, val))
+	return nil
+}
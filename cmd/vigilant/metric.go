@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+const (
+	maxMetricBatchSize   = 100
+	defaultMetricTimeout = 30 * time.Second
+)
+
+const metricUsage = `Usage: vigilant metric [flags] <name> <value>
+       vigilant metric [flags] < metrics.txt
+
+Sends metrics to Vigilant and waits until they are delivered,
+the exit code is 1 when they could not be delivered.
+
+Without a name and value the metrics are read from stdin, one per line:
+  <name> <value> [key=value]...
+Empty lines and lines starting with # are ignored.
+Negative values are arguments, not flags, e.g. vigilant metric temperature -3.
+
+Flags:
+`
+
+// tagFlags is a repeatable flag of key=value metric tags
+type tagFlags []vigilant.MetricTag
+
+// String returns the tags as key=value pairs
+func (t *tagFlags) String() string {
+	pairs := make([]string, 0, len(*t))
+	for _, tag := range *t {
+		pairs = append(pairs, tag.Key+"="+tag.Value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set parses a key=value tag
+func (t *tagFlags) Set(value string) error {
+	tag, err := parseTag(value)
+	if err != nil {
+		return err
+	}
+	*t = append(*t, tag)
+	return nil
+}
+
+// metricLine is a metric given as arguments or read from stdin
+type metricLine struct {
+	name  string
+	value float64
+	tags  []vigilant.MetricTag
+}
+
+// runMetric runs the metric command and returns the exit code
+func runMetric(args []string) int {
+	fs := flag.NewFlagSet("metric", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), metricUsage)
+		fs.PrintDefaults()
+	}
+
+	var connection connectionFlags
+	connection.register(fs)
+	var tags tagFlags
+	fs.Var(&tags, "tag", "key=value tag added to every metric, can be repeated")
+	timeout := fs.Duration("timeout", defaultMetricTimeout, "maximum time spent delivering the metrics")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	var metrics []*metricLine
+	switch len(positional) {
+	case 0:
+		metrics, err = readMetrics(os.Stdin, tags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading metrics: %v\n", err)
+			return 2
+		}
+	case 2:
+		metric, err := newMetric(positional[0], positional[1], tags)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		metrics = append(metrics, metric)
+	default:
+		fs.Usage()
+		return 2
+	}
+	if len(metrics) == 0 {
+		return 0
+	}
+
+	// every batch is exported synchronously so any rejected batch changes the exit code
+	config := connection.config()
+	exporter := vigilant.NewVigilantExporter(config)
+	defer exporter.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	failed := false
+	for start := 0; start < len(metrics); start += maxMetricBatchSize {
+		end := min(start+maxMetricBatchSize, len(metrics))
+		batch := &vigilant.MetricBatch{}
+		for _, metric := range metrics[start:end] {
+			batch.Metrics = append(batch.Metrics, metric.message(config.Attributes))
+		}
+		if err := exporter.ExportMetrics(ctx, batch); err != nil {
+			fmt.Fprintf(os.Stderr, "error sending metrics %d to %d: %v\n", start+1, end, err)
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// message creates the metric message, the attributes of the config such as service are added
+// to the tags of the metric, which take precedence like those of the SDK
+func (m *metricLine) message(attributes map[string]string) *vigilant.MetricMessage {
+	tags := slices.Clone(m.tags)
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		tags = append(tags, vigilant.Tag(key, attributes[key]))
+	}
+	return vigilant.NewMetricMessage(m.name, m.value, tags...)
+}
+
+// readMetrics reads metrics in the "<name> <value> [key=value]..." line format
+// the tags are added to every metric, tags on a line take precedence
+func readMetrics(r io.Reader, tags []vigilant.MetricTag) ([]*metricLine, error) {
+	var metrics []*metricLine
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected <name> <value> [key=value]...", line)
+		}
+
+		var lineTags []vigilant.MetricTag
+		for _, field := range fields[2:] {
+			tag, err := parseTag(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			lineTags = append(lineTags, tag)
+		}
+		lineTags = append(lineTags, tags...)
+
+		metric, err := newMetric(fields[0], fields[1], lineTags)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, scanner.Err()
+}
+
+// newMetric creates a metric from a name and a value given as text
+func newMetric(name string, value string, tags []vigilant.MetricTag) (*metricLine, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("metric %s has invalid value %q", name, value)
+	}
+	return &metricLine{name: name, value: number, tags: tags}, nil
+}
+
+// parseTag parses a key=value tag
+func parseTag(value string) (vigilant.MetricTag, error) {
+	key, val, found := strings.Cut(value, "=")
+	if !found || key == "" {
+		return vigilant.MetricTag{}, fmt.Errorf("tag %q is not key=value", value)
+	}
+	return vigilant.Tag(key, val), nil
+}
+
+// parseInterspersed parses the flags of a command that may appear before, between or after its arguments
+// it returns the arguments, everything after "--" is an argument and so is a negative number such as -3
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if len(args) > 0 && isNegativeNumber(args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+			continue
+		}
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// isNegativeNumber reports whether the argument is a negative number rather than a flag
+func isNegativeNumber(arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
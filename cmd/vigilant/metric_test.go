@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+func TestParseInterspersedNegativeNumbers(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want []string
+		tag  string
+	}{
+		{[]string{"temperature", "-3"}, []string{"temperature", "-3"}, ""},
+		{[]string{"--tag", "room=a", "temperature", "-3.5"}, []string{"temperature", "-3.5"}, "room=a"},
+		{[]string{"temperature", "-1e3", "--tag", "room=b"}, []string{"temperature", "-1e3"}, "room=b"},
+		{[]string{"--", "temperature", "-3"}, []string{"temperature", "-3"}, ""},
+	} {
+		fs := flag.NewFlagSet("metric", flag.ContinueOnError)
+		tag := fs.String("tag", "", "")
+		got, err := parseInterspersed(fs, test.args)
+		if err != nil {
+			t.Errorf("%v: %v", test.args, err)
+			continue
+		}
+		if !slices.Equal(got, test.want) || *tag != test.tag {
+			t.Errorf("%v: expected %v and tag %q, got %v and tag %q", test.args, test.want, test.tag, got, *tag)
+		}
+	}
+}
+
+func TestRunMetricAddsConfigAttributes(t *testing.T) {
+	var mux sync.Mutex
+	var metrics []*vigilant.MetricMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch struct {
+			Metrics []*vigilant.MetricMessage `json:"metrics"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mux.Lock()
+		metrics = append(metrics, batch.Metrics...)
+		mux.Unlock()
+	}))
+	defer server.Close()
+	t.Setenv(vigilant.EnvEndpoint, strings.TrimPrefix(server.URL, "http://"))
+	t.Setenv(vigilant.EnvAttributes, "env=ci")
+
+	code := runMetric([]string{"--token", "tk_test", "--insecure", "--name", "build", "temperature", "-3", "--tag", "room=a"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(metrics) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(metrics))
+	}
+	metric := metrics[0]
+	if metric.Name != "temperature" || metric.Value != -3 {
+		t.Errorf("unexpected metric %+v", metric)
+	}
+	for key, want := range map[string]string{"room": "a", "service": "build", "env": "ci"} {
+		if metric.Attributes[key] != want {
+			t.Errorf("expected attribute %s=%s, got %v", key, want, metric.Attributes)
+		}
+	}
+}
+
+func TestRunMetricFailsWhenUndelivered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	t.Setenv(vigilant.EnvEndpoint, strings.TrimPrefix(server.URL, "http://"))
+
+	if code := runMetric([]string{"--token", "tk_test", "--insecure", "temperature", "21"}); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRunMetricFailsWhenOneBatchIsRejected(t *testing.T) {
+	var mux sync.Mutex
+	requests, delivered := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var batch struct {
+			Metrics []*vigilant.MetricMessage `json:"metrics"`
+		}
+		json.NewDecoder(r.Body).Decode(&batch)
+		delivered += len(batch.Metrics)
+	}))
+	defer server.Close()
+	t.Setenv(vigilant.EnvEndpoint, strings.TrimPrefix(server.URL, "http://"))
+
+	var input strings.Builder
+	for i := range 1500 {
+		fmt.Fprintf(&input, "queue.depth %d worker=%d\n", i, i%4)
+	}
+	stdin, err := os.CreateTemp(t.TempDir(), "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin.WriteString(input.String())
+	stdin.Seek(0, io.SeekStart)
+	defer stdin.Close()
+	previous := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = previous }()
+
+	if code := runMetric([]string{"--token", "tk_test", "--insecure"}); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	if requests != 15 || delivered != 1400 {
+		t.Fatalf("expected 15 requests and 1400 delivered metrics, got %d and %d", requests, delivered)
+	}
+}
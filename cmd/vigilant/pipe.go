@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+const (
+	defaultFlushTimeout = 10 * time.Second
+	exitCodeNotStarted  = 127
+)
+
+const pipeUsage = `Usage: vigilant pipe [flags]
+
+Sends every line read from stdin to Vigilant as a log.
+
+Flags:
+`
+
+const runUsage = `Usage: vigilant run [flags] -- <command> [args]...
+
+Runs the command and sends every line it writes to Vigilant as a log,
+stdout lines are logged at INFO and stderr lines at ERROR.
+The output is still written to the terminal and the exit code of the command is returned.
+
+Flags:
+`
+
+// outputFlags are the flags shared by the pipe and run commands
+type outputFlags struct {
+	connection   connectionFlags
+	attributes   attributeFlags
+	parseLevel   bool
+	flushTimeout time.Duration
+}
+
+// register registers the output flags on the flag set
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	o.connection.register(fs)
+	fs.Var(&o.attributes, "attr", "key=value attribute added to every log, can be repeated")
+	fs.BoolVar(&o.parseLevel, "parse-level", true, "use the level found at the start of a line, e.g. \"WARN:\" or \"[error]\", or in a level=... field")
+	fs.DurationVar(&o.flushTimeout, "flush-timeout", defaultFlushTimeout, "maximum time spent sending the remaining logs before exiting")
+}
+
+// runPipe runs the pipe command and returns the exit code
+func runPipe(args []string) int {
+	fs := flag.NewFlagSet("pipe", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), pipeUsage)
+		fs.PrintDefaults()
+	}
+
+	var output outputFlags
+	output.register(fs)
+	levelName := fs.String("level", "INFO", "level of the lines without a level")
+	tee := fs.Bool("tee", false, "also write the lines to stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	level, ok := parseLevelName(*levelName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown level %q\n", *levelName)
+		return 2
+	}
+
+	client := vigilant.NewClient(output.connection.config())
+	logger := client.With(append(output.attributes, vigilant.String("stream", "stdin"))...)
+
+	var echo io.Writer
+	if *tee {
+		echo = os.Stdout
+	}
+	forwardLines(os.Stdin, echo, logger, level, output.parseLevel)
+
+	if err := shutdownClient(client, output.flushTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "error sending logs: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runRun runs the run command and returns the exit code of the child command
+func runRun(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), runUsage)
+		fs.PrintDefaults()
+	}
+
+	var output outputFlags
+	output.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	command := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	command.Stdin = os.Stdin
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error running %s: %v\n", fs.Arg(0), err)
+		return exitCodeNotStarted
+	}
+	stderr, err := command.StderrPipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error running %s: %v\n", fs.Arg(0), err)
+		return exitCodeNotStarted
+	}
+
+	client := vigilant.NewClient(output.connection.config())
+	logger := client.With(append(output.attributes, vigilant.String("command", filepath.Base(fs.Arg(0))))...)
+
+	if err := command.Start(); err != nil {
+		logger.LogErrort("command could not be started", vigilant.Error("error", err))
+		fmt.Fprintf(os.Stderr, "error running %s: %v\n", fs.Arg(0), err)
+		shutdownClient(client, output.flushTimeout)
+		return exitCodeNotStarted
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			command.Process.Signal(sig)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		forwardLines(stdout, os.Stdout, logger.With(vigilant.String("stream", "stdout")), vigilant.LEVEL_INFO, output.parseLevel)
+	}()
+	go func() {
+		defer wg.Done()
+		forwardLines(stderr, os.Stderr, logger.With(vigilant.String("stream", "stderr")), vigilant.LEVEL_ERROR, output.parseLevel)
+	}()
+	wg.Wait()
+
+	exitCode := exitCodeOf(command.Wait())
+	if exitCode == 0 {
+		logger.LogInfot("command exited", vigilant.Int("exit_code", exitCode))
+	} else {
+		logger.LogErrort("command exited", vigilant.Int("exit_code", exitCode))
+	}
+
+	if err := shutdownClient(client, output.flushTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "error sending logs: %v\n", err)
+	}
+	return exitCode
+}
+
+// forwardLines logs every line read from the reader, writing it to echo first when echo is not nil
+func forwardLines(r io.Reader, echo io.Writer, logger *vigilant.Logger, level vigilant.LogLevel, parseLevel bool) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if echo != nil {
+				io.WriteString(echo, line)
+			}
+			if text := strings.TrimRight(line, "\r\n"); text != "" {
+				lineLevel := level
+				if parseLevel {
+					if parsed, ok := parseLevelPrefix(text); ok {
+						lineLevel = parsed
+					}
+				}
+				logger.Log(lineLevel, text)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// shutdownClient shuts the client down, giving up on the remaining sends after the timeout
+func shutdownClient(client *vigilant.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.ShutdownContext(ctx)
+}
+
+// exitCodeOf returns the exit code of a finished command, 128 plus the signal number when it was killed
+func exitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// parseLevelPrefix finds the level of a line
+// it looks for a level=... field, then for a delimited level at the start of the line such as "WARN:" or "[error]",
+// a bare word is not a level so lines such as "error count: 0" keep the level of their stream
+func parseLevelPrefix(line string) (vigilant.LogLevel, bool) {
+	for _, field := range strings.Fields(line) {
+		key, value, found := strings.Cut(field, "=")
+		if found && strings.EqualFold(key, "level") {
+			return parseLevelName(strings.Trim(value, `"'`))
+		}
+	}
+
+	start := strings.TrimLeft(line, " \t")
+	if bracketed, ok := strings.CutPrefix(start, "["); ok {
+		name, _, found := strings.Cut(bracketed, "]")
+		if !found {
+			return "", false
+		}
+		return parseLevelName(name)
+	}
+	name, _, found := strings.Cut(start, ":")
+	if !found {
+		return "", false
+	}
+	return parseLevelName(name)
+}
+
+// parseLevelName converts the common spellings of a level into a LogLevel
+// it accepts the spellings of vigilant.ParseLevel and the names other loggers use for the same severities
+func parseLevelName(name string) (vigilant.LogLevel, bool) {
+	if level, err := vigilant.ParseLevel(name); err == nil {
+		return level, true
+	}
+	switch strings.ToUpper(name) {
+	case "NOTICE":
+		return vigilant.LEVEL_INFO, true
+	case "FATAL", "CRITICAL", "CRIT", "PANIC":
+		return vigilant.LEVEL_ERROR, true
+	default:
+		return "", false
+	}
+}
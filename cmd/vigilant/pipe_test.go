@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+func TestParseLevelPrefix(t *testing.T) {
+	for _, test := range []struct {
+		line  string
+		level vigilant.LogLevel
+		ok    bool
+	}{
+		{"ERROR: disk full", vigilant.LEVEL_ERROR, true},
+		{"warning: retrying", vigilant.LEVEL_WARN, true},
+		{"[error] disk full", vigilant.LEVEL_ERROR, true},
+		{"  [DEBUG] cache miss", vigilant.LEVEL_DEBUG, true},
+		{"[notice] reloaded", vigilant.LEVEL_INFO, true},
+		{"FATAL: out of memory", vigilant.LEVEL_ERROR, true},
+		{`ts=2024-01-01 level=warn msg="slow query"`, vigilant.LEVEL_WARN, true},
+		{`level="debug" msg=started`, vigilant.LEVEL_DEBUG, true},
+		{"error count: 0", "", false},
+		{"error count 0", "", false},
+		{"Info about the build", "", false},
+		{"warn", "", false},
+		{"[error disk full", "", false},
+		{"[request 42] done", "", false},
+		{"request: done", "", false},
+		{"level=verbose started", "", false},
+	} {
+		level, ok := parseLevelPrefix(test.line)
+		if level != test.level || ok != test.ok {
+			t.Errorf("%q: expected %q %v, got %q %v", test.line, test.level, test.ok, level, ok)
+		}
+	}
+}
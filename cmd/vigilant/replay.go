@@ -0,0 +1,327 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+const (
+	maxReplayBatchSize     = 100
+	replayProgressInterval = 2 * time.Second
+)
+
+const replayUsage = `Usage: vigilant replay [flags] <file|dir>...
+
+Uploads NDJSON archives written by the file exporter and spool segments to Vigilant.
+The records keep their original timestamps, gzip compressed files (.gz) are supported.
+A directory is read as a spool directory, its segments (.seg) are uploaded in the order
+they were written and are left in place, so remove them once the upload succeeded.
+
+Flags:
+`
+
+// runReplay runs the replay command and returns the exit code
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), replayUsage)
+		fs.PrintDefaults()
+	}
+
+	var connection connectionFlags
+	connection.register(fs)
+	dryRun := fs.Bool("dry-run", false, "validate the files without uploading them")
+	batchSize := fs.Int("batch-size", maxReplayBatchSize, "maximum number of records per request, at most 100")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	r := &replayer{
+		batchSize: min(max(*batchSize, 1), maxReplayBatchSize),
+		dryRun:    *dryRun,
+		out:       os.Stderr,
+	}
+	if !r.dryRun {
+		r.exporter = vigilant.NewVigilantExporter(connection.config())
+		defer r.exporter.Shutdown(context.Background())
+	}
+
+	for _, path := range fs.Args() {
+		if err := r.replayPath(ctx, path); err != nil {
+			fmt.Fprintf(os.Stderr, "error replaying %s: %v\n", path, err)
+			r.printSummary()
+			return 1
+		}
+	}
+	if err := r.flush(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "error uploading: %v\n", err)
+		r.printSummary()
+		return 1
+	}
+
+	r.printSummary()
+	if r.invalid > 0 {
+		return 1
+	}
+	return 0
+}
+
+// replayer reads records from archives and uploads them in batches
+type replayer struct {
+	exporter  vigilant.Exporter
+	batchSize int
+	dryRun    bool
+	out       io.Writer
+
+	logs        []*vigilant.LogMessage
+	metrics     vigilant.MetricBatch
+	metricCount int
+
+	files        int
+	sentLogs     int
+	sentMetrics  int
+	invalid      int
+	lastProgress time.Time
+}
+
+// replayPath uploads an archive, a spool segment or the segments of a spool directory
+func (r *replayer) replayPath(ctx context.Context, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		if filepath.Ext(path) == vigilant.SpoolSegmentExt {
+			return r.replaySegment(ctx, path)
+		}
+		return r.replayFile(ctx, path)
+	}
+
+	segments, err := vigilant.ListSpoolSegments(path)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if err := r.replaySegment(ctx, segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaySegment reads the records of a spool segment and uploads them
+// a corrupt segment is reported as invalid and skipped
+func (r *replayer) replaySegment(ctx context.Context, path string) error {
+	records, err := vigilant.ReadSpoolSegment(path)
+	if err != nil {
+		r.reportInvalid(path, err)
+		return nil
+	}
+	for i, record := range records {
+		if err := validateRecord(record); err != nil {
+			r.reportInvalid(fmt.Sprintf("%s: record %d", path, i+1), err)
+			continue
+		}
+		if err := r.add(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	r.files++
+	r.printProgress(true)
+	return nil
+}
+
+// replayFile reads the records of a file and uploads them, the last partial batch is kept for the next file
+func (r *replayer) replayFile(ctx context.Context, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	records := vigilant.NewRecordReader(reader)
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		var recordErr *vigilant.RecordError
+		if errors.As(err, &recordErr) {
+			r.reportInvalid(fmt.Sprintf("%s:%d", path, recordErr.Line), recordErr.Err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := validateRecord(record); err != nil {
+			r.reportInvalid(fmt.Sprintf("%s:%d", path, records.Line()), err)
+			continue
+		}
+
+		if err := r.add(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	r.files++
+	r.printProgress(true)
+	return nil
+}
+
+// add adds a record to the pending batches, uploading the batches that are full
+func (r *replayer) add(ctx context.Context, record *vigilant.Record) error {
+	switch {
+	case record.Log != nil:
+		r.logs = append(r.logs, record.Log)
+		if len(r.logs) >= r.batchSize {
+			return r.flushLogs(ctx)
+		}
+		return nil
+	case record.Metric != nil:
+		r.metrics.Metrics = append(r.metrics.Metrics, record.Metric)
+	case record.Counter != nil:
+		r.metrics.Counters = append(r.metrics.Counters, record.Counter)
+	case record.Gauge != nil:
+		r.metrics.Gauges = append(r.metrics.Gauges, record.Gauge)
+	case record.Histogram != nil:
+		r.metrics.Histograms = append(r.metrics.Histograms, record.Histogram)
+	}
+	r.metricCount++
+	if r.metricCount >= r.batchSize {
+		return r.flushMetrics(ctx)
+	}
+	return nil
+}
+
+// flush uploads the pending batches
+func (r *replayer) flush(ctx context.Context) error {
+	if err := r.flushLogs(ctx); err != nil {
+		return err
+	}
+	return r.flushMetrics(ctx)
+}
+
+// flushLogs uploads the pending logs
+func (r *replayer) flushLogs(ctx context.Context) error {
+	if len(r.logs) == 0 {
+		return nil
+	}
+	if !r.dryRun {
+		if err := r.exporter.ExportLogs(ctx, r.logs); err != nil {
+			return err
+		}
+	}
+	r.sentLogs += len(r.logs)
+	r.logs = nil
+	r.printProgress(false)
+	return nil
+}
+
+// flushMetrics uploads the pending metrics
+func (r *replayer) flushMetrics(ctx context.Context) error {
+	if r.metricCount == 0 {
+		return nil
+	}
+	if !r.dryRun {
+		if err := r.exporter.ExportMetrics(ctx, &r.metrics); err != nil {
+			return err
+		}
+	}
+	r.sentMetrics += r.metricCount
+	r.metrics = vigilant.MetricBatch{}
+	r.metricCount = 0
+	r.printProgress(false)
+	return nil
+}
+
+// reportInvalid reports a malformed or invalid record, the location is the file and the line or record number
+func (r *replayer) reportInvalid(location string, err error) {
+	r.invalid++
+	fmt.Fprintf(r.out, "%s: %v\n", location, err)
+}
+
+// printProgress prints the number of records uploaded so far, at most every replayProgressInterval unless forced
+func (r *replayer) printProgress(force bool) {
+	if !force && time.Since(r.lastProgress) < replayProgressInterval {
+		return
+	}
+	r.lastProgress = time.Now()
+	fmt.Fprintf(r.out, "%s %d logs and %d metrics from %d files\n", r.verb(), r.sentLogs, r.sentMetrics, r.files)
+}
+
+// printSummary prints the totals of the replay
+func (r *replayer) printSummary() {
+	fmt.Fprintf(r.out, "%s %d logs and %d metrics from %d files, %d invalid records\n", r.verb(), r.sentLogs, r.sentMetrics, r.files, r.invalid)
+}
+
+// verb describes what happens to the records
+func (r *replayer) verb() string {
+	if r.dryRun {
+		return "validated"
+	}
+	return "uploaded"
+}
+
+// validateRecord checks the fields the server requires
+func validateRecord(record *vigilant.Record) error {
+	switch {
+	case record.Log != nil:
+		if record.Log.Timestamp.IsZero() {
+			return fmt.Errorf("log without timestamp")
+		}
+		switch record.Log.Level {
+		case vigilant.LEVEL_TRACE, vigilant.LEVEL_DEBUG, vigilant.LEVEL_INFO, vigilant.LEVEL_WARN, vigilant.LEVEL_ERROR:
+		default:
+			return fmt.Errorf("log with unknown level %q", record.Log.Level)
+		}
+	case record.Metric != nil:
+		return validateMetric("metric", record.Metric.Name, record.Metric.Timestamp)
+	case record.Counter != nil:
+		return validateMetric("counter", record.Counter.MetricName, record.Counter.Timestamp)
+	case record.Gauge != nil:
+		return validateMetric("gauge", record.Gauge.MetricName, record.Gauge.Timestamp)
+	case record.Histogram != nil:
+		return validateMetric("histogram", record.Histogram.MetricName, record.Histogram.Timestamp)
+	}
+	return nil
+}
+
+// validateMetric checks the name and timestamp of a metric record
+func validateMetric(kind string, name string, timestamp time.Time) error {
+	if name == "" {
+		return fmt.Errorf("%s without name", kind)
+	}
+	if timestamp.IsZero() {
+		return fmt.Errorf("%s %s without timestamp", kind, name)
+	}
+	return nil
+}
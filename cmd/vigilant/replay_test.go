@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vigilant-run/vigilant-golang/v2"
+)
+
+// writeSpool fills a spool directory with the logs sent to a server that is down
+func writeSpool(t *testing.T, dir string, logs int) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := vigilant.NewClient(vigilant.NewConfigBuilder().
+		WithToken("tk_test").
+		WithEndpoint(strings.TrimPrefix(server.URL, "http://")).
+		WithInsecure(true).
+		WithInternalLogger(testLogger{t}).
+		WithRetry(vigilant.RetryConfig{MaxAttempts: 1}).
+		WithSpool(vigilant.SpoolConfig{Dir: dir}).
+		Build())
+	for range logs {
+		client.LogInfo("spooled")
+	}
+	client.Shutdown()
+}
+
+// testLogger is an InternalLogger writing to the test log
+type testLogger struct {
+	t *testing.T
+}
+
+// Printf writes the message to the test log
+func (l testLogger) Printf(format string, args ...any) {
+	l.t.Logf(format, args...)
+}
+
+func TestReplaySpoolDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeSpool(t, dir, 5)
+	if err := os.WriteFile(filepath.Join(dir, "99999999999999999999-0000000001"+vigilant.SpoolSegmentExt), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &replayer{batchSize: maxReplayBatchSize, dryRun: true, out: io.Discard}
+	if err := r.replayPath(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if r.sentLogs != 5 || r.invalid != 1 {
+		t.Fatalf("expected 5 logs and 1 invalid segment, got %d logs and %d invalid", r.sentLogs, r.invalid)
+	}
+}
+
+func TestReplaySpoolSegmentUploads(t *testing.T) {
+	dir := t.TempDir()
+	writeSpool(t, dir, 3)
+	segments, err := vigilant.ListSpoolSegments(dir)
+	if err != nil || len(segments) == 0 {
+		t.Fatalf("expected segments, got %v %v", segments, err)
+	}
+
+	received := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer server.Close()
+	t.Setenv(vigilant.EnvEndpoint, strings.TrimPrefix(server.URL, "http://"))
+
+	if code := runReplay(append([]string{"--token", "tk_test", "--insecure"}, segments...)); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the segment to be uploaded")
+	}
+}
@@ -0,0 +1,61 @@
+package vigilant
+
+import (
+	"bytes"
+	"compress/gzip"
+	"sync"
+)
+
+const (
+	defaultCompressionMinSize = 1024
+)
+
+// Codec compresses request bodies sent to the server
+// implementations must be safe for concurrent use
+type Codec interface {
+	// Encoding is the Content-Encoding value of the compressed body, e.g. "gzip"
+	Encoding() string
+
+	// Compress appends the compressed form of src to dst
+	Compress(dst *bytes.Buffer, src []byte) error
+}
+
+// gzipCodec is a Codec that compresses with gzip, reusing writers from a pool
+type gzipCodec struct {
+	level   int
+	writers sync.Pool
+}
+
+// NewGzipCodec creates a gzip Codec with the given compression level, such as gzip.DefaultCompression
+// an invalid level falls back to gzip.DefaultCompression
+func NewGzipCodec(level int) Codec {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	return &gzipCodec{level: level}
+}
+
+// Encoding returns the Content-Encoding of gzip compressed bodies
+func (c *gzipCodec) Encoding() string {
+	return "gzip"
+}
+
+// Compress appends the gzip compressed form of src to dst
+func (c *gzipCodec) Compress(dst *bytes.Buffer, src []byte) error {
+	writer, ok := c.writers.Get().(*gzip.Writer)
+	if ok {
+		writer.Reset(dst)
+	} else {
+		var err error
+		writer, err = gzip.NewWriterLevel(dst, c.level)
+		if err != nil {
+			return err
+		}
+	}
+	defer c.writers.Put(writer)
+
+	if _, err := writer.Write(src); err != nil {
+		return err
+	}
+	return writer.Close()
+}
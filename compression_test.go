@@ -0,0 +1,104 @@
+package vigilant
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gunzip decompresses a gzip body
+func gunzip(body []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+func TestGzipCodecRoundTrip(t *testing.T) {
+	for _, level := range []int{gzip.BestSpeed, gzip.BestCompression, 42} {
+		codec := NewGzipCodec(level)
+		if encoding := codec.Encoding(); encoding != "gzip" {
+			t.Fatalf("expected the gzip encoding, got %s", encoding)
+		}
+
+		// the writers are pooled, so compress concurrently to check they are reset between uses
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				src := []byte(strings.Repeat(fmt.Sprintf("payload %d ", i), 100+i))
+				var dst bytes.Buffer
+				if err := codec.Compress(&dst, src); err != nil {
+					t.Error(err)
+					return
+				}
+				if dst.Len() >= len(src) {
+					t.Errorf("level %d: expected the body to shrink, got %d bytes from %d", level, dst.Len(), len(src))
+				}
+				if got, err := gunzip(dst.Bytes()); err != nil || !bytes.Equal(got, src) {
+					t.Errorf("level %d: the round trip changed the body: %v", level, err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func TestTransportCompressesLargeBodies(t *testing.T) {
+	var mux sync.Mutex
+	encodings := make(map[int]string)
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mux.Lock()
+		encodings[len(bodies)] = r.Header.Get("Content-Encoding")
+		bodies = append(bodies, body)
+		mux.Unlock()
+	}))
+	defer server.Close()
+
+	transport := newHTTPTransport("tk_test", server.URL, server.Client(), RetryConfig{MaxAttempts: 1}, CompressionConfig{
+		Codec:   NewGzipCodec(gzip.DefaultCompression),
+		MinSize: 100,
+	}, nil, nil, defaultDiagnostics)
+
+	small := []byte(`{"logs":[]}`)
+	large := []byte(`{"logs":[` + strings.Repeat(`{"body":"compressed"},`, 50) + `{"body":"last"}]}`)
+	for _, body := range [][]byte{small, large} {
+		if err := transport.sendWithRetry(context.Background(), logEndpoint, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(bodies))
+	}
+	if encodings[0] != "" || !bytes.Equal(bodies[0], small) {
+		t.Errorf("expected the body below MinSize to be sent as it is, got %q with encoding %q", bodies[0], encodings[0])
+	}
+	if encodings[1] != "gzip" {
+		t.Fatalf("expected the gzip Content-Encoding, got %q", encodings[1])
+	}
+	got, err := gunzip(bodies[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, large) {
+		t.Errorf("expected the decompressed body to match the batch, got %s", got)
+	}
+}
@@ -1,6 +1,10 @@
 package vigilant
 
-import "maps"
+import (
+	"maps"
+	"slices"
+	"time"
+)
 
 // VigilantConfig is the configuration for Vigilant
 type VigilantConfig struct {
@@ -10,6 +14,9 @@ type VigilantConfig struct {
 	// Level is the level of logs Vigilant will send to the server
 	Level LogLevel
 
+	// LoggerLevels are the levels of the named loggers, they take precedence over Level
+	LoggerLevels map[string]LogLevel
+
 	// Token is the Vigilant API token
 	Token string
 
@@ -25,20 +32,170 @@ type VigilantConfig struct {
 	// Noop is whether to not send logs to the server
 	Noop bool
 
-	// Attributes are the attributes to add to all logs
+	// Attributes are the attributes to add to all logs and metrics
 	Attributes map[string]string
+
+	// Retry is the retry policy used when sending batches to the server
+	Retry RetryConfig
+
+	// Compression is the compression applied to request bodies, it is disabled when Codec is nil
+	Compression CompressionConfig
+
+	// Spool is the on-disk spool for batches that could not be sent, it is disabled when Dir is empty
+	Spool SpoolConfig
+
+	// Batch is how the logs and metrics from MetricEvent are grouped into batches
+	Batch BatchConfig
+
+	// Exporters are the destinations of the logs and metrics, the Vigilant exporter is used when empty
+	// the client shuts the exporters down when it is shut down
+	Exporters []Exporter
+
+	// LogQueue is the queue of logs waiting to be batched
+	LogQueue QueueConfig
+
+	// MetricQueue is the queue of metrics waiting to be batched
+	MetricQueue QueueConfig
+
+	// CollectorQueue is the queue of counter, gauge and histogram events waiting to be aggregated
+	CollectorQueue QueueConfig
+
+	// SelfTelemetry is whether to send the stats of the pipelines as gauges every collector interval
+	SelfTelemetry bool
+
+	// ErrorHandler receives the errors that cannot be returned to the caller, such as failed batch sends,
+	// they are written to InternalLogger when nil, it is called from the pipeline goroutines and should not block
+	ErrorHandler func(error)
+
+	// InternalLogger receives the diagnostic messages of the SDK, they are printed to stdout when nil
+	InternalLogger InternalLogger
+
+	// fileExporters are the exporters of the config file, they are created by the client when Exporters is empty
+	fileExporters []fileExporterConfig
+}
+
+// EndpointURL returns the base URL the batches are sent to, built from the endpoint and the insecure setting
+func (c *VigilantConfig) EndpointURL() string {
+	return getEndpoint(c)
+}
+
+// RetryConfig is the retry policy used when sending batches to the server
+// network errors and 408, 429 and 5xx responses are retried, other responses fail immediately
+// zero fields use the defaults, set MaxAttempts to 1 to disable retries
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts for a batch, including the first one
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry, it doubles after every attempt
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between attempts, it also caps Retry-After delays
+	MaxBackoff time.Duration
+}
+
+// withDefaults returns the retry config with the zero fields set to the defaults
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = defaultMaxAttempts
+	}
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = defaultInitialBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = defaultMaxBackoff
+	}
+	return r
+}
+
+// CompressionConfig is the compression applied to request bodies sent to the server
+// the body is compressed with Codec and sent with the matching Content-Encoding header
+type CompressionConfig struct {
+	// Codec compresses the bodies, use NewGzipCodec or a custom Codec such as zstd
+	Codec Codec
+
+	// MinSize is the body size in bytes below which bodies are sent uncompressed
+	MinSize int
+}
+
+// withDefaults returns the compression config with the zero fields set to the defaults
+func (c CompressionConfig) withDefaults() CompressionConfig {
+	if c.MinSize <= 0 {
+		c.MinSize = defaultCompressionMinSize
+	}
+	return c
+}
+
+// BatchConfig is how logs and metrics are grouped into batches before being exported
+// zero fields use the defaults
+type BatchConfig struct {
+	// MaxSize is the maximum number of items in a batch, at most 100
+	MaxSize int
+
+	// Interval is how often the batches that are not full are exported
+	Interval time.Duration
+}
+
+// withDefaults returns the batch config with the zero fields set to the defaults
+func (c BatchConfig) withDefaults() BatchConfig {
+	if c.MaxSize <= 0 || c.MaxSize > maxBatchSize {
+		c.MaxSize = maxBatchSize
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaultBatchInterval
+	}
+	return c
+}
+
+// SpoolConfig is the configuration of the on-disk spool
+// batches that still fail after all retries, or whose send is cut short by shutdown, are written to the spool directory
+// and replayed in order once the server is reachable again or the next time the program starts
+// the spool is not a write-ahead log: logs and metrics still held in memory are lost if the process crashes
+type SpoolConfig struct {
+	// Dir is the directory the spooled batches are written to, only batches whose send failed are written to it
+	Dir string
+
+	// MaxBytes is the maximum total size of the spool, the oldest batches are removed above it
+	MaxBytes int64
+
+	// MaxAge is the maximum age of a spooled batch, older batches are removed
+	MaxAge time.Duration
+}
+
+// withDefaults returns the spool config with the zero fields set to the defaults
+func (s SpoolConfig) withDefaults() SpoolConfig {
+	if s.MaxBytes <= 0 {
+		s.MaxBytes = defaultSpoolMaxBytes
+	}
+	if s.MaxAge <= 0 {
+		s.MaxAge = defaultSpoolMaxAge
+	}
+	return s
 }
 
 // VigilantConfigBuilder is the builder for the VigilantConfig
 type VigilantConfigBuilder struct {
-	name        *string
-	level       *LogLevel
-	token       *string
-	endpoint    *string
-	passthrough *bool
-	insecure    *bool
-	noop        *bool
-	attributes  map[string]string
+	name           *string
+	level          *LogLevel
+	loggerLevels   map[string]LogLevel
+	token          *string
+	endpoint       *string
+	passthrough    *bool
+	insecure       *bool
+	noop           *bool
+	attributes     map[string]string
+	retry          *RetryConfig
+	compression    *CompressionConfig
+	spool          *SpoolConfig
+	batch          *BatchConfig
+	exporters      []Exporter
+	logQueue       *QueueConfig
+	metricQueue    *QueueConfig
+	collectorQueue *QueueConfig
+	selfTelemetry  *bool
+	errorHandler   func(error)
+	internalLogger InternalLogger
+	env            bool
+	file           *fileConfig
 }
 
 // NewConfigBuilder creates a new VigilantConfig builder
@@ -58,6 +215,12 @@ func (b *VigilantConfigBuilder) WithLevel(level LogLevel) *VigilantConfigBuilder
 	return b
 }
 
+// WithLoggerLevels sets the levels of the named loggers, e.g. from ParseLoggerLevels("db=DEBUG,http=WARN")
+func (b *VigilantConfigBuilder) WithLoggerLevels(levels map[string]LogLevel) *VigilantConfigBuilder {
+	b.loggerLevels = levels
+	return b
+}
+
 // WithToken sets the token of the service
 func (b *VigilantConfigBuilder) WithToken(token string) *VigilantConfigBuilder {
 	b.token = &token
@@ -94,17 +257,99 @@ func (b *VigilantConfigBuilder) WithAttributes(attributes ...Attribute) *Vigilan
 	return b
 }
 
+// WithRetry sets the retry policy used when sending batches to the server
+func (b *VigilantConfigBuilder) WithRetry(retry RetryConfig) *VigilantConfigBuilder {
+	b.retry = &retry
+	return b
+}
+
+// WithCompression sets the compression applied to request bodies
+func (b *VigilantConfigBuilder) WithCompression(compression CompressionConfig) *VigilantConfigBuilder {
+	b.compression = &compression
+	return b
+}
+
+// WithSpool sets the on-disk spool for batches that could not be sent
+func (b *VigilantConfigBuilder) WithSpool(spool SpoolConfig) *VigilantConfigBuilder {
+	b.spool = &spool
+	return b
+}
+
+// WithBatch sets the size and interval of the batches
+func (b *VigilantConfigBuilder) WithBatch(batch BatchConfig) *VigilantConfigBuilder {
+	b.batch = &batch
+	return b
+}
+
+// WithExporters sets the destinations of the logs and metrics, replacing the Vigilant exporter
+// include NewVigilantExporter to keep sending to Vigilant alongside the other exporters
+func (b *VigilantConfigBuilder) WithExporters(exporters ...Exporter) *VigilantConfigBuilder {
+	b.exporters = exporters
+	return b
+}
+
+// WithLogQueue sets the capacity and overflow policy of the log queue
+func (b *VigilantConfigBuilder) WithLogQueue(queue QueueConfig) *VigilantConfigBuilder {
+	b.logQueue = &queue
+	return b
+}
+
+// WithMetricQueue sets the capacity and overflow policy of the metric queue
+func (b *VigilantConfigBuilder) WithMetricQueue(queue QueueConfig) *VigilantConfigBuilder {
+	b.metricQueue = &queue
+	return b
+}
+
+// WithCollectorQueue sets the capacity and overflow policy of the collector event queues
+func (b *VigilantConfigBuilder) WithCollectorQueue(queue QueueConfig) *VigilantConfigBuilder {
+	b.collectorQueue = &queue
+	return b
+}
+
+// WithSelfTelemetry sets whether to send the stats of the pipelines as vigilant_sdk_* gauges every collector interval
+func (b *VigilantConfigBuilder) WithSelfTelemetry(selfTelemetry bool) *VigilantConfigBuilder {
+	b.selfTelemetry = &selfTelemetry
+	return b
+}
+
+// WithErrorHandler sets the function receiving the errors that cannot be returned to the caller
+// the errors of failed batch sends are *SendError, and dropped logs are *CaptureError
+func (b *VigilantConfigBuilder) WithErrorHandler(handler func(error)) *VigilantConfigBuilder {
+	b.errorHandler = handler
+	return b
+}
+
+// WithInternalLogger sets the logger receiving the diagnostic messages of the SDK, e.g. a *log.Logger writing to stderr
+func (b *VigilantConfigBuilder) WithInternalLogger(logger InternalLogger) *VigilantConfigBuilder {
+	b.internalLogger = logger
+	return b
+}
+
 // Build builds the VigilantConfig
 func (b *VigilantConfigBuilder) Build() *VigilantConfig {
 	config := &VigilantConfig{
-		Name:        "server-name",
-		Level:       LEVEL_TRACE,
-		Token:       "tk_1234567890",
-		Endpoint:    "ingress.vigilant.run",
-		Passthrough: false,
-		Insecure:    false,
-		Noop:        false,
-		Attributes:  map[string]string{"service": "server-name"},
+		Name:           "server-name",
+		Level:          LEVEL_TRACE,
+		Token:          placeholderToken,
+		Endpoint:       "ingress.vigilant.run",
+		Passthrough:    false,
+		Insecure:       false,
+		Noop:           false,
+		Attributes:     map[string]string{"service": "server-name"},
+		Retry:          RetryConfig{}.withDefaults(),
+		Batch:          BatchConfig{}.withDefaults(),
+		LogQueue:       QueueConfig{}.withDefaults(),
+		MetricQueue:    QueueConfig{}.withDefaults(),
+		CollectorQueue: QueueConfig{}.withDefaults(),
+	}
+
+	if b.file != nil {
+		b.file.apply(config)
+	}
+
+	var envErrs []error
+	if b.env {
+		envErrs = applyEnv(config)
 	}
 
 	if b.name != nil {
@@ -116,6 +361,10 @@ func (b *VigilantConfigBuilder) Build() *VigilantConfig {
 		config.Level = *b.level
 	}
 
+	if len(b.loggerLevels) > 0 {
+		config.LoggerLevels = maps.Clone(b.loggerLevels)
+	}
+
 	if b.token != nil {
 		config.Token = *b.token
 	}
@@ -140,19 +389,75 @@ func (b *VigilantConfigBuilder) Build() *VigilantConfig {
 		maps.Copy(config.Attributes, b.attributes)
 	}
 
+	if b.retry != nil {
+		config.Retry = b.retry.withDefaults()
+	}
+
+	if b.compression != nil {
+		config.Compression = b.compression.withDefaults()
+	}
+
+	if b.spool != nil {
+		config.Spool = b.spool.withDefaults()
+	}
+
+	if b.batch != nil {
+		config.Batch = b.batch.withDefaults()
+	}
+
+	if b.logQueue != nil {
+		config.LogQueue = b.logQueue.withDefaults()
+	}
+
+	if b.metricQueue != nil {
+		config.MetricQueue = b.metricQueue.withDefaults()
+	}
+
+	if b.collectorQueue != nil {
+		config.CollectorQueue = b.collectorQueue.withDefaults()
+	}
+
+	if b.selfTelemetry != nil {
+		config.SelfTelemetry = *b.selfTelemetry
+	}
+
+	if b.errorHandler != nil {
+		config.ErrorHandler = b.errorHandler
+	}
+
+	if b.internalLogger != nil {
+		config.InternalLogger = b.internalLogger
+	}
+
+	diag := newDiagnostics(config)
+	for _, err := range envErrs {
+		diag.reportError(err)
+	}
+
+	if len(b.exporters) > 0 {
+		config.Exporters = b.exporters
+	} else if b.file != nil {
+		config.fileExporters = slices.Clone(b.file.Exporters)
+	}
+
 	return config
 }
 
 // NewNoopConfig creates a new noop VigilantConfig, this is useful for testing
 func NewNoopConfig() *VigilantConfig {
 	return &VigilantConfig{
-		Name:        "server-name",
-		Level:       LEVEL_TRACE,
-		Token:       "tk_1234567890",
-		Endpoint:    "ingress.vigilant.run",
-		Insecure:    false,
-		Passthrough: true,
-		Noop:        true,
-		Attributes:  map[string]string{},
+		Name:           "server-name",
+		Level:          LEVEL_TRACE,
+		Token:          placeholderToken,
+		Endpoint:       "ingress.vigilant.run",
+		Insecure:       false,
+		Passthrough:    true,
+		Noop:           true,
+		Attributes:     map[string]string{},
+		Retry:          RetryConfig{}.withDefaults(),
+		Batch:          BatchConfig{}.withDefaults(),
+		LogQueue:       QueueConfig{}.withDefaults(),
+		MetricQueue:    QueueConfig{}.withDefaults(),
+		CollectorQueue: QueueConfig{}.withDefaults(),
 	}
 }
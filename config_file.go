@@ -0,0 +1,357 @@
+package vigilant
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// exporter types of the config file
+const (
+	fileExporterVigilant = "vigilant"
+	fileExporterOTLP     = "otlp"
+	fileExporterFile     = "file"
+)
+
+// fileConfig is the JSON document read by LoadConfigFile
+// every field is optional, the fields that are not set keep the value of the previous source
+type fileConfig struct {
+	Name           *string              `json:"name"`
+	Level          *string              `json:"level"`
+	LoggerLevels   map[string]string    `json:"logger_levels"`
+	Token          *string              `json:"token"`
+	Endpoint       *string              `json:"endpoint"`
+	Passthrough    *bool                `json:"passthrough"`
+	Insecure       *bool                `json:"insecure"`
+	Noop           *bool                `json:"noop"`
+	Attributes     map[string]string    `json:"attributes"`
+	Retry          *fileRetryConfig     `json:"retry"`
+	Compression    *fileCompression     `json:"compression"`
+	Spool          *fileSpoolConfig     `json:"spool"`
+	Batch          *fileBatchConfig     `json:"batch"`
+	Exporters      []fileExporterConfig `json:"exporters"`
+	LogQueue       *fileQueueConfig     `json:"log_queue"`
+	MetricQueue    *fileQueueConfig     `json:"metric_queue"`
+	CollectorQueue *fileQueueConfig     `json:"collector_queue"`
+	SelfTelemetry  *bool                `json:"self_telemetry"`
+}
+
+// fileRetryConfig is the retry section of the config file
+type fileRetryConfig struct {
+	MaxAttempts    int          `json:"max_attempts"`
+	InitialBackoff fileDuration `json:"initial_backoff"`
+	MaxBackoff     fileDuration `json:"max_backoff"`
+}
+
+// fileCompression is the compression section of the config file
+type fileCompression struct {
+	Codec   string `json:"codec"`
+	Level   *int   `json:"level"`
+	MinSize int    `json:"min_size"`
+}
+
+// fileSpoolConfig is the spool section of the config file
+type fileSpoolConfig struct {
+	Dir      string       `json:"dir"`
+	MaxBytes int64        `json:"max_bytes"`
+	MaxAge   fileDuration `json:"max_age"`
+}
+
+// fileBatchConfig is the batch section of the config file
+type fileBatchConfig struct {
+	MaxSize  int          `json:"max_size"`
+	Interval fileDuration `json:"interval"`
+}
+
+// fileQueueConfig is a queue section of the config file
+type fileQueueConfig struct {
+	Capacity int          `json:"capacity"`
+	Policy   string       `json:"policy"`
+	Timeout  fileDuration `json:"timeout"`
+}
+
+// fileExporterConfig is an entry of the exporters section of the config file
+// Type selects the exporter, the other fields are the settings of the OTLP and file exporters
+type fileExporterConfig struct {
+	Type string `json:"type"`
+
+	Endpoint        string            `json:"endpoint"`
+	Headers         map[string]string `json:"headers"`
+	HistogramBounds []float64         `json:"histogram_bounds"`
+
+	Path     string       `json:"path"`
+	MaxBytes int64        `json:"max_bytes"`
+	MaxAge   fileDuration `json:"max_age"`
+	MaxFiles int          `json:"max_files"`
+	Compress bool         `json:"compress"`
+}
+
+// fileDuration is a duration written as a string such as "500ms" or "1m30s"
+type fileDuration time.Duration
+
+// UnmarshalJSON parses the duration string
+func (d *fileDuration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string such as \"500ms\"")
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = fileDuration(duration)
+	return nil
+}
+
+// LoadConfigFile reads a JSON config file and returns a builder holding its settings
+// the settings are merged in this order, each source overriding the previous one:
+// the defaults, the file, the environment variables when WithEnv is used, and the values set on the builder
+// unknown keys and invalid values are returned as a *ConfigError each, so typos are caught
+func LoadConfigFile(path string) (*VigilantConfigBuilder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if _, ok := raw.(map[string]any); !ok {
+		return nil, fmt.Errorf("error parsing %s: the document must be a JSON object", path)
+	}
+
+	errs := unknownKeys(raw, reflect.TypeFor[fileConfig](), "")
+	if len(errs) == 0 {
+		var file fileConfig
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		errs = file.validate()
+		if len(errs) == 0 {
+			return &VigilantConfigBuilder{file: &file}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error loading %s: %w", path, errors.Join(errs...))
+}
+
+// validate checks the values that are not checked by VigilantConfig.Validate
+func (f *fileConfig) validate() []error {
+	var errs []error
+	if f.Level != nil {
+		if _, err := ParseLevel(*f.Level); err != nil {
+			errs = append(errs, &ConfigError{Field: "level", Value: *f.Level, Reason: "level must be one of TRACE, DEBUG, INFO, WARNING or ERROR"})
+		}
+	}
+	for name, level := range f.LoggerLevels {
+		if _, err := ParseLevel(level); err != nil {
+			errs = append(errs, &ConfigError{Field: "logger_levels." + name, Value: level, Reason: "level must be one of TRACE, DEBUG, INFO, WARNING or ERROR"})
+		}
+	}
+	if f.Compression != nil {
+		switch f.Compression.Codec {
+		case "", "none", "gzip":
+		default:
+			errs = append(errs, &ConfigError{Field: "compression.codec", Value: f.Compression.Codec, Reason: "codec must be gzip or none"})
+		}
+	}
+	for name, queue := range map[string]*fileQueueConfig{"log_queue": f.LogQueue, "metric_queue": f.MetricQueue, "collector_queue": f.CollectorQueue} {
+		if queue == nil {
+			continue
+		}
+		switch OverflowPolicy(queue.Policy) {
+		case "", OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowBlockTimeout:
+		default:
+			errs = append(errs, &ConfigError{Field: name + ".policy", Value: queue.Policy, Reason: "policy must be block, drop_newest, drop_oldest or block_timeout"})
+		}
+	}
+	for i, exporter := range f.Exporters {
+		field := fmt.Sprintf("exporters[%d]", i)
+		switch exporter.Type {
+		case fileExporterVigilant, fileExporterOTLP:
+		case fileExporterFile:
+			if exporter.Path == "" {
+				errs = append(errs, &ConfigError{Field: field + ".path", Reason: "path is empty"})
+			}
+		default:
+			errs = append(errs, &ConfigError{Field: field + ".type", Value: exporter.Type, Reason: "type must be vigilant, otlp or file"})
+		}
+	}
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return errs
+}
+
+// apply sets the config fields that are set in the file
+func (f *fileConfig) apply(config *VigilantConfig) {
+	if f.Name != nil {
+		config.Name = *f.Name
+		config.Attributes["service"] = *f.Name
+	}
+	if f.Level != nil {
+		config.Level, _ = ParseLevel(*f.Level)
+	}
+	if len(f.LoggerLevels) > 0 {
+		config.LoggerLevels = make(map[string]LogLevel, len(f.LoggerLevels))
+		for name, level := range f.LoggerLevels {
+			config.LoggerLevels[name], _ = ParseLevel(level)
+		}
+	}
+	if f.Token != nil {
+		config.Token = *f.Token
+	}
+	if f.Endpoint != nil {
+		config.Endpoint = *f.Endpoint
+	}
+	if f.Passthrough != nil {
+		config.Passthrough = *f.Passthrough
+	}
+	if f.Insecure != nil {
+		config.Insecure = *f.Insecure
+	}
+	if f.Noop != nil {
+		config.Noop = *f.Noop
+	}
+	maps.Copy(config.Attributes, f.Attributes)
+
+	if f.Retry != nil {
+		config.Retry = RetryConfig{
+			MaxAttempts:    f.Retry.MaxAttempts,
+			InitialBackoff: time.Duration(f.Retry.InitialBackoff),
+			MaxBackoff:     time.Duration(f.Retry.MaxBackoff),
+		}.withDefaults()
+	}
+	if f.Compression != nil && f.Compression.Codec == "gzip" {
+		level := gzip.DefaultCompression
+		if f.Compression.Level != nil {
+			level = *f.Compression.Level
+		}
+		config.Compression = CompressionConfig{
+			Codec:   NewGzipCodec(level),
+			MinSize: f.Compression.MinSize,
+		}.withDefaults()
+	}
+	if f.Spool != nil {
+		config.Spool = SpoolConfig{
+			Dir:      f.Spool.Dir,
+			MaxBytes: f.Spool.MaxBytes,
+			MaxAge:   time.Duration(f.Spool.MaxAge),
+		}.withDefaults()
+	}
+	if f.Batch != nil {
+		config.Batch = BatchConfig{
+			MaxSize:  f.Batch.MaxSize,
+			Interval: time.Duration(f.Batch.Interval),
+		}.withDefaults()
+	}
+	if f.LogQueue != nil {
+		config.LogQueue = f.LogQueue.queueConfig()
+	}
+	if f.MetricQueue != nil {
+		config.MetricQueue = f.MetricQueue.queueConfig()
+	}
+	if f.CollectorQueue != nil {
+		config.CollectorQueue = f.CollectorQueue.queueConfig()
+	}
+	if f.SelfTelemetry != nil {
+		config.SelfTelemetry = *f.SelfTelemetry
+	}
+}
+
+// queueConfig converts the queue section into a QueueConfig
+func (q *fileQueueConfig) queueConfig() QueueConfig {
+	return QueueConfig{
+		Capacity: q.Capacity,
+		Policy:   OverflowPolicy(q.Policy),
+		Timeout:  time.Duration(q.Timeout),
+	}.withDefaults()
+}
+
+// newFileExporters creates the exporters of the config file, it is called by the client that uses the config
+// an exporter that cannot be created is reported and left out
+func newFileExporters(config *VigilantConfig) []Exporter {
+	diag := newDiagnostics(config)
+	var exporters []Exporter
+	for _, exporter := range config.fileExporters {
+		switch exporter.Type {
+		case fileExporterVigilant:
+			exporters = append(exporters, NewVigilantExporter(config))
+		case fileExporterOTLP:
+			exporters = append(exporters, NewOTLPExporter(config, OTLPConfig{
+				Endpoint:        exporter.Endpoint,
+				Headers:         exporter.Headers,
+				HistogramBounds: exporter.HistogramBounds,
+			}))
+		case fileExporterFile:
+			fileExporter, err := NewFileExporter(FileConfig{
+				Path:         exporter.Path,
+				MaxBytes:     exporter.MaxBytes,
+				MaxAge:       time.Duration(exporter.MaxAge),
+				MaxFiles:     exporter.MaxFiles,
+				Compress:     exporter.Compress,
+				ErrorHandler: diag.reportError,
+			})
+			if err != nil {
+				diag.reportError(fmt.Errorf("error creating file exporter, continuing without it: %w", err))
+				continue
+			}
+			exporters = append(exporters, fileExporter)
+		}
+	}
+	return exporters
+}
+
+// unknownKeys returns a *ConfigError for every key of the decoded JSON value that has no matching field in the type
+func unknownKeys(value any, t reflect.Type, path string) []error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok || t == reflect.TypeFor[fileDuration]() {
+			return nil
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			fields[name] = t.Field(i).Type
+		}
+		var errs []error
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			fieldType, ok := fields[key]
+			if !ok {
+				errs = append(errs, &ConfigError{Field: fieldPath, Reason: "unknown key"})
+				continue
+			}
+			errs = append(errs, unknownKeys(object[key], fieldType, fieldPath)...)
+		}
+		return errs
+	case reflect.Slice:
+		array, ok := value.([]any)
+		if !ok {
+			return nil
+		}
+		var errs []error
+		for i, item := range array {
+			errs = append(errs, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	default:
+		return nil
+	}
+}
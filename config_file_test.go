@@ -0,0 +1,133 @@
+package vigilant
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes a config file in a temporary directory and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vigilant.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFileReportsUnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"name": "api",
+		"retry": {"max_attempt": 3},
+		"exporters": [{"type": "file", "path": "out.ndjson", "rotate": true}],
+		"tokn": "tk_abc"
+	}`)
+
+	_, err := LoadConfigFile(path)
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	for _, field := range []string{"retry.max_attempt", "exporters[0].rotate", "tokn"} {
+		if !strings.Contains(err.Error(), "invalid "+field+": unknown key") {
+			t.Errorf("expected %s to be reported, got %v", field, err)
+		}
+	}
+}
+
+func TestLoadConfigFileReportsInvalidValues(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"level": "LOUD",
+		"logger_levels": {"db": "VERBOSE"},
+		"compression": {"codec": "zstd"},
+		"log_queue": {"policy": "drop_all"},
+		"exporters": [{"type": "kafka"}, {"type": "file"}]
+	}`)
+
+	_, err := LoadConfigFile(path)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, field := range []string{"level", "logger_levels.db", "compression.codec", "log_queue.policy", "exporters[0].type", "exporters[1].path"} {
+		if !strings.Contains(err.Error(), "invalid "+field) {
+			t.Errorf("expected %s to be reported, got %v", field, err)
+		}
+	}
+}
+
+func TestLoadConfigFileMergeOrder(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"name": "from-file",
+		"level": "warn",
+		"token": "tk_file",
+		"endpoint": "file.example.com",
+		"attributes": {"region": "eu", "tier": "file"},
+		"batch": {"max_size": 50, "interval": "1s"},
+		"log_queue": {"capacity": 10, "policy": "drop_newest"}
+	}`)
+	t.Setenv(EnvToken, "tk_env")
+	t.Setenv(EnvEndpoint, "env.example.com")
+	t.Setenv(EnvAttributes, "tier=env")
+
+	builder, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := builder.WithEnv().WithEndpoint("builder.example.com").Build()
+
+	if config.Name != "from-file" || config.Attributes["service"] != "from-file" {
+		t.Errorf("expected the name of the file, got %q", config.Name)
+	}
+	if config.Level != LEVEL_WARN {
+		t.Errorf("expected the level of the file, got %q", config.Level)
+	}
+	if config.Token != "tk_env" {
+		t.Errorf("expected the environment to override the file, got %q", config.Token)
+	}
+	if config.Endpoint != "builder.example.com" {
+		t.Errorf("expected the builder to override the environment, got %q", config.Endpoint)
+	}
+	if config.Attributes["region"] != "eu" || config.Attributes["tier"] != "env" {
+		t.Errorf("expected merged attributes, got %v", config.Attributes)
+	}
+	if config.Batch.MaxSize != 50 || config.Batch.Interval != time.Second {
+		t.Errorf("expected the batch of the file, got %+v", config.Batch)
+	}
+	if config.LogQueue.Capacity != 10 || config.LogQueue.Policy != OverflowDropNewest {
+		t.Errorf("expected the log queue of the file, got %+v", config.LogQueue)
+	}
+	if config.MetricQueue != (QueueConfig{}.withDefaults()) {
+		t.Errorf("expected the default metric queue, got %+v", config.MetricQueue)
+	}
+}
+
+func TestLoadConfigFileCreatesExportersWithClient(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out", "telemetry.ndjson")
+	path := writeConfigFile(t, `{"noop": false, "exporters": [{"type": "file", "path": "`+filepath.ToSlash(output)+`"}]}`)
+
+	builder, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := builder.Build()
+	builder.Build()
+	if len(config.Exporters) != 0 {
+		t.Fatalf("expected Build to create no exporters, got %d", len(config.Exporters))
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("expected Build to leave %s alone, got %v", output, err)
+	}
+
+	client := NewClient(config)
+	client.LogInfo("to the file")
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if records := readRecords(t, output); len(records) != 1 || records[0].Log == nil || records[0].Log.Body != "to the file" {
+		t.Fatalf("expected the log in %s, got %v", output, records)
+	}
+}
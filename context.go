@@ -0,0 +1,55 @@
+package vigilant
+
+import (
+	"context"
+	"maps"
+)
+
+// contextAttributesKey is the context key for request-scoped attributes
+type contextAttributesKey struct{}
+
+// WithAttributes returns a copy of the context carrying the given attributes
+// the attributes are added to every log and metric captured with the returned context,
+// attributes already in the context are kept unless overwritten by a key in attributes
+//
+// Example:
+//
+//	ctx = vigilant.WithAttributes(ctx, vigilant.String("request_id", requestID))
+//	vigilant.LogInfoCtx(ctx, "Handling request")
+func WithAttributes(ctx context.Context, attributes ...Attribute) context.Context {
+	existing := attributesFromContext(ctx)
+	merged := make(map[string]string, len(existing)+len(attributes))
+	maps.Copy(merged, existing)
+	maps.Copy(merged, attributesToMap(attributes...))
+	return context.WithValue(ctx, contextAttributesKey{}, merged)
+}
+
+// attributesFromContext returns the attributes stored in the context, the map must not be modified
+func attributesFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(contextAttributesKey{}).(map[string]string)
+	return attrs
+}
+
+// contextAttributes merges the context attributes with the given attributes
+// the given attributes take precedence over the context attributes
+func contextAttributes(ctx context.Context, attributes ...Attribute) map[string]string {
+	attrs := maps.Clone(attributesFromContext(ctx))
+	if attrs == nil {
+		attrs = make(map[string]string, len(attributes))
+	}
+	maps.Copy(attrs, attributesToMap(attributes...))
+	return attrs
+}
+
+// contextTags returns the context attributes as metric tags
+func contextTags(ctx context.Context) []MetricTag {
+	attrs := attributesFromContext(ctx)
+	tags := make([]MetricTag, 0, len(attrs))
+	for key, value := range attrs {
+		tags = append(tags, Tag(key, value))
+	}
+	return tags
+}
@@ -0,0 +1,56 @@
+package vigilant
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"testing"
+)
+
+func TestContextAttributesAreMerged(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).Build())
+
+	ctx := WithAttributes(context.Background(), String("request_id", "r1"), String("user_id", "u1"))
+	ctx = WithAttributes(ctx, String("user_id", "u2"))
+	client.LogInfoCtx(ctx, "client", String("request_id", "call"))
+	client.With(String("component", "billing")).LogWarnCtx(ctx, "logger")
+	slog.New(client.SlogHandler()).InfoContext(ctx, "slog", "step", "charge")
+	client.MetricEventCtx(ctx, "charges", 1, Tag("user_id", "call"))
+	client.LogInfoCtx(context.Background(), "no attributes")
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mux.Lock()
+	defer server.mux.Unlock()
+	logs := make(map[string]map[string]string)
+	var metrics []*MetricMessage
+	for _, batch := range server.batches {
+		for _, log := range batch.Logs {
+			delete(log.Attributes, "service")
+			logs[log.Body] = log.Attributes
+		}
+		metrics = append(metrics, batch.Metrics...)
+	}
+
+	for body, want := range map[string]map[string]string{
+		"client":        {"request_id": "call", "user_id": "u2"},
+		"logger":        {"request_id": "r1", "user_id": "u2", "component": "billing"},
+		"slog":          {"request_id": "r1", "user_id": "u2", "step": "charge"},
+		"no attributes": {},
+	} {
+		if got := logs[body]; !maps.Equal(got, want) {
+			t.Errorf("log %q: expected %v, got %v", body, want, got)
+		}
+	}
+
+	if len(metrics) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(metrics))
+	}
+	attrs := metrics[0].Attributes
+	delete(attrs, "service")
+	if want := map[string]string{"request_id": "r1", "user_id": "call"}; !maps.Equal(attrs, want) {
+		t.Errorf("expected the metric tags %v, got %v", want, attrs)
+	}
+}
@@ -0,0 +1,174 @@
+package vigilant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// names of the pipelines in SendError, CaptureError and the self-telemetry metrics
+const (
+	PipelineLogs      = "logs"
+	PipelineMetrics   = "metrics"
+	PipelineCollector = "collector"
+)
+
+// InternalLogger receives the diagnostic messages of the SDK, such as ignored settings
+// *log.Logger implements it
+type InternalLogger interface {
+	Printf(format string, args ...any)
+}
+
+// defaultInternalLogger prints the diagnostic messages to stdout like earlier versions
+var defaultInternalLogger InternalLogger = log.New(os.Stdout, "", 0)
+
+// SendError is a batch that could not be exported, after the retries
+type SendError struct {
+	// Pipeline is the pipeline of the batch: PipelineLogs, PipelineMetrics or PipelineCollector
+	Pipeline string
+
+	// Items is the number of logs, metrics or aggregated series in the batch
+	Items int
+
+	// Err is the error of the exporter
+	Err error
+}
+
+// Error returns the string representation of the error
+func (e *SendError) Error() string {
+	return fmt.Sprintf("error sending batch of %d %s: %v", e.Items, pipelineItems(e.Pipeline), e.Err)
+}
+
+// Unwrap returns the error of the exporter
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// CaptureError is a log or metric that was dropped before reaching its pipeline, e.g. because of invalid key-value pairs
+type CaptureError struct {
+	// Pipeline is the pipeline the item was captured for
+	Pipeline string
+
+	// Err is the reason the item was dropped
+	Err error
+}
+
+// Error returns the string representation of the error
+func (e *CaptureError) Error() string {
+	return fmt.Sprintf("dropped 1 item of the %s pipeline: %v", e.Pipeline, e.Err)
+}
+
+// Unwrap returns the reason the item was dropped
+func (e *CaptureError) Unwrap() error {
+	return e.Err
+}
+
+// pipelineItems returns the name of the items of a pipeline for error messages
+func pipelineItems(pipeline string) string {
+	if pipeline == PipelineCollector {
+		return "aggregated metrics"
+	}
+	return pipeline
+}
+
+// diagnostics routes the errors and diagnostic messages of a client to the handlers of its config
+type diagnostics struct {
+	logger       InternalLogger
+	errorHandler func(error)
+}
+
+// defaultDiagnostics is used before any config is known
+var defaultDiagnostics = &diagnostics{logger: defaultInternalLogger}
+
+// globalDiagnostics is the diagnostics of the last config passed to Init, used by the package-level functions
+var globalDiagnostics atomic.Pointer[diagnostics]
+
+// packageSettings holds the handlers set with SetInternalLogger and SetErrorHandler, packageSettingsMux serializes the updates
+var (
+	packageSettingsMux sync.Mutex
+	packageSettings    atomic.Pointer[diagnostics]
+)
+
+// SetInternalLogger sets the logger of the diagnostic messages of the package-level functions,
+// e.g. the warning printed when Vigilant is used before Init
+// it takes precedence over the InternalLogger of the config passed to Init, nil removes it
+func SetInternalLogger(logger InternalLogger) {
+	updatePackageSettings(func(settings *diagnostics) {
+		settings.logger = logger
+	})
+}
+
+// SetErrorHandler sets the handler of the errors of the package-level functions that cannot be returned,
+// e.g. the invalid key-value pairs of a Logger without a client
+// it takes precedence over the ErrorHandler of the config passed to Init, nil removes it
+func SetErrorHandler(handler func(error)) {
+	updatePackageSettings(func(settings *diagnostics) {
+		settings.errorHandler = handler
+	})
+}
+
+// updatePackageSettings applies the update to a copy of the package settings and stores it
+func updatePackageSettings(update func(settings *diagnostics)) {
+	packageSettingsMux.Lock()
+	defer packageSettingsMux.Unlock()
+
+	var settings diagnostics
+	if current := packageSettings.Load(); current != nil {
+		settings = *current
+	}
+	update(&settings)
+	packageSettings.Store(&settings)
+}
+
+// newDiagnostics creates the diagnostics of the config
+func newDiagnostics(config *VigilantConfig) *diagnostics {
+	d := &diagnostics{
+		logger:       config.InternalLogger,
+		errorHandler: config.ErrorHandler,
+	}
+	if d.logger == nil {
+		d.logger = defaultInternalLogger
+	}
+	return d
+}
+
+// packageDiagnostics returns the diagnostics of the package-level functions
+// the package settings take precedence over the diagnostics of the config passed to Init
+func packageDiagnostics() *diagnostics {
+	d := defaultDiagnostics
+	if global := globalDiagnostics.Load(); global != nil {
+		d = global
+	}
+
+	settings := packageSettings.Load()
+	if settings == nil || (settings.logger == nil && settings.errorHandler == nil) {
+		return d
+	}
+	merged := *d
+	if settings.logger != nil {
+		merged.logger = settings.logger
+	}
+	if settings.errorHandler != nil {
+		merged.errorHandler = settings.errorHandler
+	}
+	return &merged
+}
+
+// logf writes a diagnostic message
+func (d *diagnostics) logf(format string, args ...any) {
+	d.logger.Printf(format, args...)
+}
+
+// reportError hands an error that cannot be returned to the error handler, or logs it when there is none
+func (d *diagnostics) reportError(err error) {
+	if err == nil {
+		return
+	}
+	if d.errorHandler != nil {
+		d.errorHandler(err)
+		return
+	}
+	d.logger.Printf("%v", err)
+}
@@ -0,0 +1,81 @@
+package vigilant
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingLogger is an InternalLogger that keeps the messages
+type recordingLogger struct {
+	mux      sync.Mutex
+	messages []string
+}
+
+// Printf records the message
+func (l *recordingLogger) Printf(format string, args ...any) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+// joined returns the recorded messages on one string
+func (l *recordingLogger) joined() string {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return strings.Join(l.messages, "\n")
+}
+
+// resetPackageSettings removes the package settings when the test ends
+func resetPackageSettings(t *testing.T) {
+	t.Cleanup(func() {
+		SetInternalLogger(nil)
+		SetErrorHandler(nil)
+		globalNilErrorEmitted.Store(false)
+	})
+}
+
+func TestSetInternalLoggerReceivesNotInitializedWarning(t *testing.T) {
+	resetPackageSettings(t)
+	logger := &recordingLogger{}
+	SetInternalLogger(logger)
+	globalNilErrorEmitted.Store(false)
+
+	LogInfo("before init")
+	MetricEvent("before_init", 1)
+
+	if messages := logger.joined(); strings.Count(messages, "Vigilant is not initialized") != 1 {
+		t.Fatalf("expected a single warning, got %q", messages)
+	}
+}
+
+func TestSetErrorHandlerTakesPrecedenceOverInit(t *testing.T) {
+	resetPackageSettings(t)
+	var mux sync.Mutex
+	var errs []error
+	SetErrorHandler(func(err error) {
+		mux.Lock()
+		errs = append(errs, err)
+		mux.Unlock()
+	})
+
+	server := newTestServer(t, nil)
+	configErrs := 0
+	if err := InitE(testConfig(server).WithErrorHandler(func(error) { configErrs++ }).Build()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+
+	packageDiagnostics().reportError(errors.New("package error"))
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(errs) != 1 || configErrs != 0 {
+		t.Fatalf("expected the package handler to get the error, got %v and %d errors of the config", errs, configErrs)
+	}
+	if packageDiagnostics().logger != (discardLogger{}) {
+		t.Fatal("expected the logger of the config passed to Init to be kept")
+	}
+}
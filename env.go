@@ -0,0 +1,119 @@
+package vigilant
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// environment variables read by WithEnv
+const (
+	EnvToken         = "VIGILANT_TOKEN"
+	EnvEndpoint      = "VIGILANT_ENDPOINT"
+	EnvLevel         = "VIGILANT_LEVEL"
+	EnvLoggerLevels  = "VIGILANT_LOGGER_LEVELS"
+	EnvName          = "VIGILANT_NAME"
+	EnvInsecure      = "VIGILANT_INSECURE"
+	EnvNoop          = "VIGILANT_NOOP"
+	EnvAttributes    = "VIGILANT_ATTRIBUTES"
+	EnvSelfTelemetry = "VIGILANT_SELF_TELEMETRY"
+)
+
+// NewConfigFromEnv creates a VigilantConfig from the VIGILANT_* environment variables
+// the variables that are not set use the defaults of the builder
+func NewConfigFromEnv() *VigilantConfig {
+	return NewConfigBuilder().WithEnv().Build()
+}
+
+// WithEnv makes the builder read the VIGILANT_* environment variables when the config is built
+// values set explicitly on the builder take precedence over the environment, whatever the call order,
+// attributes from VIGILANT_ATTRIBUTES are a comma separated list of key=value pairs, e.g. env=prod,region=eu,
+// and VIGILANT_LOGGER_LEVELS is a list of name=level pairs, e.g. db=DEBUG,http=WARN
+func (b *VigilantConfigBuilder) WithEnv() *VigilantConfigBuilder {
+	b.env = true
+	return b
+}
+
+// applyEnv sets the config fields of the environment variables that are set
+// invalid values are ignored and returned as a *ConfigError each, for the caller to report
+func applyEnv(config *VigilantConfig) []error {
+	var errs []error
+
+	if name, ok := os.LookupEnv(EnvName); ok && name != "" {
+		config.Name = name
+		config.Attributes["service"] = name
+	}
+
+	if level, ok := os.LookupEnv(EnvLevel); ok && level != "" {
+		if parsed, err := ParseLevel(level); err == nil {
+			config.Level = parsed
+		} else {
+			errs = append(errs, &ConfigError{Field: EnvLevel, Value: level, Reason: "level must be one of TRACE, DEBUG, INFO, WARNING or ERROR, ignoring it"})
+		}
+	}
+
+	if loggers, ok := os.LookupEnv(EnvLoggerLevels); ok && loggers != "" {
+		if parsed, err := ParseLoggerLevels(loggers); err == nil {
+			config.LoggerLevels = parsed
+		} else {
+			errs = append(errs, &ConfigError{Field: EnvLoggerLevels, Value: loggers, Reason: err.Error() + ", ignoring it"})
+		}
+	}
+
+	if token, ok := os.LookupEnv(EnvToken); ok && token != "" {
+		config.Token = token
+	}
+
+	if endpoint, ok := os.LookupEnv(EnvEndpoint); ok && endpoint != "" {
+		config.Endpoint = endpoint
+	}
+
+	if insecure, ok, err := lookupEnvBool(EnvInsecure); ok {
+		config.Insecure = insecure
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+
+	if noop, ok, err := lookupEnvBool(EnvNoop); ok {
+		config.Noop = noop
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+
+	if selfTelemetry, ok, err := lookupEnvBool(EnvSelfTelemetry); ok {
+		config.SelfTelemetry = selfTelemetry
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+
+	if attributes, ok := os.LookupEnv(EnvAttributes); ok {
+		for _, pair := range strings.Split(attributes, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			key, value, found := strings.Cut(pair, "=")
+			key = strings.TrimSpace(key)
+			if !found || key == "" {
+				errs = append(errs, &ConfigError{Field: EnvAttributes, Value: pair, Reason: "attribute is not key=value, ignoring it"})
+				continue
+			}
+			config.Attributes[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return errs
+}
+
+// lookupEnvBool reads a boolean environment variable, it returns a *ConfigError for an invalid value
+func lookupEnvBool(key string) (bool, bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return false, false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false, &ConfigError{Field: key, Value: value, Reason: "value must be true or false, ignoring it"}
+	}
+	return parsed, true, nil
+}
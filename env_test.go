@@ -0,0 +1,140 @@
+package vigilant
+
+import (
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// envConfigErrors builds a config from the environment and returns the fields of the reported errors
+func envConfigErrors(t *testing.T, builder *VigilantConfigBuilder) (*VigilantConfig, []string) {
+	t.Helper()
+	var mux sync.Mutex
+	var fields []string
+	config := builder.WithEnv().WithErrorHandler(func(err error) {
+		mux.Lock()
+		fields = append(fields, configErrorFields(err)...)
+		mux.Unlock()
+	}).Build()
+
+	mux.Lock()
+	defer mux.Unlock()
+	return config, fields
+}
+
+func TestEnvAppliesEveryVariable(t *testing.T) {
+	t.Setenv(EnvName, "api")
+	t.Setenv(EnvToken, "tk_env")
+	t.Setenv(EnvEndpoint, "env.example.com")
+	t.Setenv(EnvLevel, "warning")
+	t.Setenv(EnvLoggerLevels, "db=debug, http=ERROR")
+	t.Setenv(EnvInsecure, "true")
+	t.Setenv(EnvNoop, "1")
+	t.Setenv(EnvSelfTelemetry, "TRUE")
+	t.Setenv(EnvAttributes, " region = eu ,, tier=gold")
+
+	config, fields := envConfigErrors(t, NewConfigBuilder())
+	if len(fields) != 0 {
+		t.Fatalf("expected no errors, got %v", fields)
+	}
+	if config.Name != "api" || config.Token != "tk_env" || config.Endpoint != "env.example.com" {
+		t.Errorf("unexpected name, token or endpoint: %q %q %q", config.Name, config.Token, config.Endpoint)
+	}
+	if config.Level != LEVEL_WARN {
+		t.Errorf("expected the level to be parsed, got %q", config.Level)
+	}
+	if want := map[string]LogLevel{"db": LEVEL_DEBUG, "http": LEVEL_ERROR}; !maps.Equal(config.LoggerLevels, want) {
+		t.Errorf("expected the logger levels %v, got %v", want, config.LoggerLevels)
+	}
+	if !config.Insecure || !config.Noop || !config.SelfTelemetry {
+		t.Errorf("expected the booleans to be set, got insecure %v noop %v self telemetry %v", config.Insecure, config.Noop, config.SelfTelemetry)
+	}
+	if want := map[string]string{"service": "api", "region": "eu", "tier": "gold"}; !maps.Equal(config.Attributes, want) {
+		t.Errorf("expected the attributes %v, got %v", want, config.Attributes)
+	}
+}
+
+func TestEnvBuilderTakesPrecedenceWhateverTheOrder(t *testing.T) {
+	t.Setenv(EnvLevel, "DEBUG")
+	t.Setenv(EnvEndpoint, "env.example.com")
+	t.Setenv(EnvAttributes, "tier=env,region=eu")
+
+	before := NewConfigBuilder().WithEnv().WithLevel(LEVEL_ERROR).WithAttributes(String("tier", "builder")).Build()
+	after := NewConfigBuilder().WithLevel(LEVEL_ERROR).WithAttributes(String("tier", "builder")).WithEnv().Build()
+	for _, config := range []*VigilantConfig{before, after} {
+		if config.Level != LEVEL_ERROR {
+			t.Errorf("expected the level of the builder, got %q", config.Level)
+		}
+		if config.Endpoint != "env.example.com" {
+			t.Errorf("expected the endpoint of the environment, got %q", config.Endpoint)
+		}
+		if config.Attributes["tier"] != "builder" || config.Attributes["region"] != "eu" {
+			t.Errorf("expected the attributes of the builder over those of the environment, got %v", config.Attributes)
+		}
+	}
+
+	t.Setenv(EnvLevel, "")
+	if config := NewConfigBuilder().WithEnv().Build(); config.Level != LEVEL_TRACE {
+		t.Errorf("expected an empty variable to be ignored, got %q", config.Level)
+	}
+}
+
+func TestEnvReportsInvalidValues(t *testing.T) {
+	t.Setenv(EnvLoggerLevels, "db=LOUD")
+	t.Setenv(EnvNoop, "yes please")
+	t.Setenv(EnvSelfTelemetry, "sometimes")
+
+	config, fields := envConfigErrors(t, NewConfigBuilder())
+	want := []string{EnvLoggerLevels, EnvNoop, EnvSelfTelemetry}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected errors for %v, got %v", want, fields)
+	}
+	if len(config.LoggerLevels) != 0 || config.Noop || config.SelfTelemetry {
+		t.Errorf("expected the invalid values to be ignored, got %v %v %v", config.LoggerLevels, config.Noop, config.SelfTelemetry)
+	}
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	t.Setenv(EnvName, "from-env")
+	t.Setenv(EnvToken, "tk_env")
+	t.Setenv(EnvLevel, "LOUD")
+	t.Setenv(EnvInsecure, "maybe")
+	t.Setenv(EnvAttributes, "region=eu,broken")
+
+	var mux sync.Mutex
+	var errs []error
+	config := NewConfigBuilder().
+		WithToken("tk_builder").
+		WithEnv().
+		WithErrorHandler(func(err error) {
+			mux.Lock()
+			errs = append(errs, err)
+			mux.Unlock()
+		}).
+		Build()
+
+	if config.Name != "from-env" || config.Attributes["service"] != "from-env" {
+		t.Errorf("expected the name of the environment, got %q", config.Name)
+	}
+	if config.Token != "tk_builder" {
+		t.Errorf("expected the builder to override the environment, got %q", config.Token)
+	}
+	if config.Level != LEVEL_TRACE || config.Insecure {
+		t.Errorf("expected invalid values to be ignored, got level %q and insecure %v", config.Level, config.Insecure)
+	}
+	if config.Attributes["region"] != "eu" {
+		t.Errorf("expected the attributes of the environment, got %v", config.Attributes)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, configErrorFields(err)...)
+	}
+	want := []string{EnvLevel, EnvInsecure, EnvAttributes}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected errors for %v, got %v", want, fields)
+	}
+}
@@ -0,0 +1,98 @@
+package vigilant
+
+import (
+	"context"
+	"errors"
+)
+
+// Exporter is a destination for the logs and metrics captured by Vigilant
+// the batching pipelines call the export functions from their own goroutines,
+// so implementations must be safe for concurrent use
+type Exporter interface {
+	// ExportLogs exports a batch of logs
+	ExportLogs(ctx context.Context, logs []*LogMessage) error
+
+	// ExportMetrics exports a batch of metrics
+	ExportMetrics(ctx context.Context, batch *MetricBatch) error
+
+	// Shutdown releases the resources of the exporter, it is called after the last export
+	// it may be called more than once, e.g. when the exporter is shared by several clients
+	Shutdown(ctx context.Context) error
+}
+
+// startableExporter is implemented by the built-in exporters with background work
+// the client starts its exporter when it starts, so creating an exporter starts nothing
+type startableExporter interface {
+	start()
+}
+
+// startExporter starts the exporter if it has background work
+func startExporter(exporter Exporter) {
+	if startable, ok := exporter.(startableExporter); ok {
+		startable.start()
+	}
+}
+
+// MetricBatch is a batch of metrics handed to an Exporter
+// metrics from MetricEvent are in Metrics, the client-side aggregated metrics are in the other fields
+type MetricBatch struct {
+	Metrics    []*MetricMessage
+	Counters   []*CounterMessage
+	Gauges     []*GaugeMessage
+	Histograms []*HistogramMessage
+}
+
+// NewMetricMessage creates a metric message timestamped now, for exporting it directly with an Exporter
+func NewMetricMessage(name string, value float64, tags ...MetricTag) *MetricMessage {
+	return createMetricMessage(name, value, tags...)
+}
+
+// empty reports whether the batch holds no metrics
+func (b *MetricBatch) empty() bool {
+	return len(b.Metrics) == 0 && len(b.Counters) == 0 && len(b.Gauges) == 0 && len(b.Histograms) == 0
+}
+
+// multiExporter is an Exporter that writes to several exporters
+type multiExporter struct {
+	exporters []Exporter
+}
+
+// NewMultiExporter creates an Exporter that writes every batch to all the given exporters
+// a failing exporter does not stop the others, the returned error joins the failures
+func NewMultiExporter(exporters ...Exporter) Exporter {
+	return &multiExporter{exporters: exporters}
+}
+
+// ExportLogs exports the logs to every exporter
+func (m *multiExporter) ExportLogs(ctx context.Context, logs []*LogMessage) error {
+	var errs []error
+	for _, exporter := range m.exporters {
+		errs = append(errs, exporter.ExportLogs(ctx, logs))
+	}
+	return errors.Join(errs...)
+}
+
+// ExportMetrics exports the metrics to every exporter
+func (m *multiExporter) ExportMetrics(ctx context.Context, batch *MetricBatch) error {
+	var errs []error
+	for _, exporter := range m.exporters {
+		errs = append(errs, exporter.ExportMetrics(ctx, batch))
+	}
+	return errors.Join(errs...)
+}
+
+// start starts the exporters that have background work
+func (m *multiExporter) start() {
+	for _, exporter := range m.exporters {
+		startExporter(exporter)
+	}
+}
+
+// Shutdown shuts down every exporter
+func (m *multiExporter) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, exporter := range m.exporters {
+		errs = append(errs, exporter.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,358 @@
+package vigilant
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFileMaxBytes = 100 * 1024 * 1024
+	defaultFileMaxAge   = 24 * time.Hour
+	defaultFileMaxFiles = 10
+	fileRotationLayout  = "20060102T150405.000000000"
+	fileGzipExt         = ".gz"
+	fileTempExt         = ".tmp"
+)
+
+// record types of the lines written by the file exporter
+const (
+	fileRecordLog       = "log"
+	fileRecordMetric    = "metric"
+	fileRecordCounter   = "counter"
+	fileRecordGauge     = "gauge"
+	fileRecordHistogram = "histogram"
+)
+
+// FileConfig is the configuration of the file exporter
+// zero fields other than Path use the defaults
+type FileConfig struct {
+	// Path is the file the records are written to, e.g. /var/log/vigilant/telemetry.ndjson
+	Path string
+
+	// MaxBytes is the size above which the file is rotated
+	MaxBytes int64
+
+	// MaxAge is the age above which the file is rotated
+	MaxAge time.Duration
+
+	// MaxFiles is the number of rotated files kept, the oldest are removed
+	MaxFiles int
+
+	// Compress is whether rotated files are compressed with gzip
+	Compress bool
+
+	// ErrorHandler receives the errors of the background compression of rotated files,
+	// they are printed to stdout when nil
+	ErrorHandler func(error)
+}
+
+// withDefaults returns the file config with the zero fields set to the defaults
+func (f FileConfig) withDefaults() FileConfig {
+	if f.MaxBytes <= 0 {
+		f.MaxBytes = defaultFileMaxBytes
+	}
+	if f.MaxAge <= 0 {
+		f.MaxAge = defaultFileMaxAge
+	}
+	if f.MaxFiles <= 0 {
+		f.MaxFiles = defaultFileMaxFiles
+	}
+	return f
+}
+
+// fileExporter is the Exporter that writes records to a rotating file as newline-delimited JSON
+// every line is a log or metric message with an added "type" field,
+// rotated files are renamed with their rotation time, then pruned and optionally compressed by a background worker
+type fileExporter struct {
+	config FileConfig
+	diag   *diagnostics
+
+	mux    sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	rotations   chan struct{}
+	startWorker sync.Once
+	stopWorker  sync.Once
+	wg          sync.WaitGroup
+}
+
+// NewFileExporter creates an Exporter that writes logs and metrics to a rotating NDJSON file
+// the directory is created if needed and an existing file is appended to
+func NewFileExporter(config FileConfig) (Exporter, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("file exporter path is empty")
+	}
+	config = config.withDefaults()
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0o755); err != nil {
+		return nil, err
+	}
+
+	e := &fileExporter{
+		config:    config,
+		diag:      &diagnostics{logger: defaultInternalLogger, errorHandler: config.ErrorHandler},
+		rotations: make(chan struct{}, 1),
+	}
+	if err := e.open(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// ExportLogs writes the logs to the file
+func (e *fileExporter) ExportLogs(ctx context.Context, logs []*LogMessage) error {
+	var buf bytes.Buffer
+	for _, log := range logs {
+		if err := appendFileRecord(&buf, fileRecordLog, log); err != nil {
+			return err
+		}
+	}
+	return e.write(buf.Bytes())
+}
+
+// ExportMetrics writes the metrics to the file
+func (e *fileExporter) ExportMetrics(ctx context.Context, batch *MetricBatch) error {
+	if batch == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, metric := range batch.Metrics {
+		if err := appendFileRecord(&buf, fileRecordMetric, metric); err != nil {
+			return err
+		}
+	}
+	for _, counter := range batch.Counters {
+		if err := appendFileRecord(&buf, fileRecordCounter, counter); err != nil {
+			return err
+		}
+	}
+	for _, gauge := range batch.Gauges {
+		if err := appendFileRecord(&buf, fileRecordGauge, gauge); err != nil {
+			return err
+		}
+	}
+	for _, histogram := range batch.Histograms {
+		if err := appendFileRecord(&buf, fileRecordHistogram, histogram); err != nil {
+			return err
+		}
+	}
+	return e.write(buf.Bytes())
+}
+
+// Shutdown closes the file and waits for the worker to finish with the rotated files
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	e.mux.Lock()
+	var err error
+	if e.file != nil {
+		err = e.file.Close()
+		e.file = nil
+	}
+	e.stopWorker.Do(func() { close(e.rotations) })
+	e.mux.Unlock()
+
+	e.wg.Wait()
+	return err
+}
+
+// write appends the lines to the file, rotating it first when it is too large or too old
+func (e *fileExporter) write(lines []byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if e.file == nil {
+		return fmt.Errorf("file exporter is shut down")
+	}
+
+	if e.size > 0 && (e.size+int64(len(lines)) > e.config.MaxBytes || time.Since(e.opened) > e.config.MaxAge) {
+		if err := e.rotate(); err != nil {
+			return fmt.Errorf("error rotating %s: %w", e.config.Path, err)
+		}
+	}
+
+	n, err := e.file.Write(lines)
+	e.size += int64(n)
+	return err
+}
+
+// open opens the file for appending, the lock is expected to be held by the caller
+func (e *fileExporter) open() error {
+	file, err := os.OpenFile(e.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	e.file = file
+	e.size = info.Size()
+	e.opened = time.Now()
+	return nil
+}
+
+// rotate renames the current file with the rotation time and opens a new one
+// the lock is expected to be held by the caller
+func (e *fileExporter) rotate() error {
+	if err := e.file.Close(); err != nil {
+		return err
+	}
+	e.file = nil
+
+	ext := filepath.Ext(e.config.Path)
+	base := strings.TrimSuffix(e.config.Path, ext)
+	rotatedPath := base + "-" + time.Now().UTC().Format(fileRotationLayout) + ext
+	if err := os.Rename(e.config.Path, rotatedPath); err != nil {
+		if openErr := e.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+
+	e.startWorker.Do(func() {
+		e.wg.Add(1)
+		go e.runRotationWorker()
+	})
+	select {
+	case e.rotations <- struct{}{}:
+	default:
+	}
+
+	return e.open()
+}
+
+// runRotationWorker prunes and compresses the rotated files after every rotation until the exporter is shut down
+// a single worker handles every rotation, so a file is never removed while it is being compressed
+func (e *fileExporter) runRotationWorker() {
+	defer e.wg.Done()
+	for range e.rotations {
+		rotated := e.removeOldFiles()
+		if !e.config.Compress {
+			continue
+		}
+		for _, path := range rotated {
+			if strings.HasSuffix(path, fileGzipExt) {
+				continue
+			}
+			if err := compressFile(path); err != nil {
+				e.diag.reportError(fmt.Errorf("error compressing rotated file %s: %w", path, err))
+			}
+		}
+	}
+}
+
+// removeOldFiles removes the oldest rotated files above the retention limit and returns the files kept
+// only the files named by rotate are considered, so other files next to the path are never removed
+func (e *fileExporter) removeOldFiles() []string {
+	dir := filepath.Dir(e.config.Path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var rotated []string
+	for _, entry := range entries {
+		if !entry.IsDir() && e.isRotatedFile(entry.Name()) {
+			rotated = append(rotated, filepath.Join(dir, entry.Name()))
+		}
+	}
+	slices.Sort(rotated)
+	for len(rotated) > e.config.MaxFiles {
+		os.Remove(rotated[0])
+		rotated = rotated[1:]
+	}
+	return rotated
+}
+
+// isRotatedFile reports whether a file name is a rotated file of the path, compressed or not,
+// e.g. telemetry-20240102T150405.000000000.ndjson for telemetry.ndjson
+func (e *fileExporter) isRotatedFile(name string) bool {
+	ext := filepath.Ext(e.config.Path)
+	prefix := strings.TrimSuffix(filepath.Base(e.config.Path), ext) + "-"
+
+	name = strings.TrimSuffix(name, fileGzipExt)
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	stamp, ok := strings.CutSuffix(rest, ext)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(fileRotationLayout, stamp)
+	return err == nil
+}
+
+// compressFile replaces a file with its gzip compressed form
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tempPath := path + fileGzipExt + fileTempExt
+	dst, err := os.Create(tempPath)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(dst)
+	_, err = io.Copy(writer, src)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path+fileGzipExt)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// appendFileRecord appends a message as a line of the given record type
+// the line is the JSON object of the message with the "type" field added first
+func appendFileRecord[T any](buf *bytes.Buffer, recordType string, message *T) error {
+	if message == nil {
+		return nil
+	}
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if len(messageBytes) < 2 || messageBytes[0] != '{' {
+		return fmt.Errorf("%s record is not an object", recordType)
+	}
+
+	fmt.Fprintf(buf, `{"type":%q`, recordType)
+	if len(messageBytes) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.Write(messageBytes[1:])
+	buf.WriteByte('\n')
+	return nil
+}
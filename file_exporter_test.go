@@ -0,0 +1,239 @@
+package vigilant
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// readRecords reads the records of a file written by the file exporter, decompressing it if needed
+func readRecords(t *testing.T, path string) []*Record {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, fileGzipExt) {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		r = gz
+	}
+
+	var records []*Record
+	reader := NewRecordReader(r)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestFileExporterRotatesAndCompresses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "telemetry.ndjson")
+
+	var mux sync.Mutex
+	var errs []error
+	exporter, err := NewFileExporter(FileConfig{
+		Path:     path,
+		MaxBytes: 300,
+		MaxFiles: 2,
+		Compress: true,
+		ErrorHandler: func(err error) {
+			mux.Lock()
+			errs = append(errs, err)
+			mux.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 200 {
+		if err := exporter.ExportLogs(context.Background(), []*LogMessage{createLogMessage(LEVEL_INFO, "rotated", nil)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	for _, err := range errs {
+		t.Error(err)
+	}
+
+	rotated, err := filepath.Glob(filepath.Join(dir, "telemetry-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", rotated)
+	}
+	for _, file := range rotated {
+		if !strings.HasSuffix(file, ".ndjson"+fileGzipExt) {
+			t.Errorf("expected %s to be compressed", file)
+		}
+		if records := readRecords(t, file); len(records) == 0 {
+			t.Errorf("expected records in %s", file)
+		}
+	}
+	if records := readRecords(t, path); len(records) == 0 {
+		t.Error("expected records in the current file")
+	}
+}
+
+func TestFileExporterShutdownTwice(t *testing.T) {
+	exporter, err := NewFileExporter(FileConfig{Path: filepath.Join(t.TempDir(), "telemetry.ndjson"), MaxBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		exporter.ExportLogs(context.Background(), []*LogMessage{createLogMessage(LEVEL_INFO, "line", nil)})
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.ExportLogs(context.Background(), []*LogMessage{createLogMessage(LEVEL_INFO, "late", nil)}); err == nil {
+		t.Fatal("expected an error after shutdown")
+	}
+}
+
+func TestFileExporterOnlyRemovesRotatedFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs[1]")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "telemetry.ndjson")
+	unrelated := []string{"telemetry-backup.ndjson", "telemetry-old.ndjson.gz", "telemetry-20240102.ndjson"}
+	for _, name := range unrelated {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("keep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exporter, err := NewFileExporter(FileConfig{Path: path, MaxBytes: 1, MaxFiles: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		if err := exporter.ExportLogs(context.Background(), []*LogMessage{createLogMessage(LEVEL_INFO, "line", nil)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range unrelated {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be kept: %v", name, err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(unrelated)+2 {
+		t.Fatalf("expected the current file and 1 rotated file next to the unrelated files, got %d entries", len(entries))
+	}
+}
+
+func TestFileExporterRotatesOnMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "telemetry.ndjson")
+	exporter, err := NewFileExporter(FileConfig{Path: path, MaxAge: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	export := func(body string) {
+		if err := exporter.ExportLogs(context.Background(), []*LogMessage{createLogMessage(LEVEL_INFO, body, nil)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	export("first")
+	export("second")
+	rotated, _ := filepath.Glob(filepath.Join(dir, "telemetry-*"))
+	if len(rotated) != 0 {
+		t.Fatalf("expected no rotation before MaxAge, got %v", rotated)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	export("third")
+	rotated, _ = filepath.Glob(filepath.Join(dir, "telemetry-*"))
+	if len(rotated) != 1 {
+		t.Fatalf("expected 1 rotated file after MaxAge, got %v", rotated)
+	}
+	if records := readRecords(t, rotated[0]); len(records) != 2 {
+		t.Errorf("expected the 2 records written before MaxAge in the rotated file, got %d", len(records))
+	}
+	if records := readRecords(t, path); len(records) != 1 || records[0].Log.Body != "third" {
+		t.Errorf("expected the current file to hold the last record, got %v", records)
+	}
+}
+
+func TestFileExporterConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "telemetry.ndjson")
+	exporter, err := NewFileExporter(FileConfig{Path: path, MaxBytes: 2000, MaxFiles: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const writers, exports = 8, 50
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range exports {
+				if err := exporter.ExportLogs(context.Background(), []*LogMessage{createLogMessage(LEVEL_INFO, "concurrent", nil)}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "telemetry*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("expected the writers to rotate the file, got %v", files)
+	}
+	count := 0
+	for _, file := range files {
+		count += len(readRecords(t, file))
+	}
+	if count != writers*exports {
+		t.Fatalf("expected %d records, got %d", writers*exports, count)
+	}
+}
@@ -0,0 +1,47 @@
+package vigilant
+
+// SetGlobalAttributes replaces the global attributes of the Vigilant instance
+// the global attributes are added to every log and metric, the service attribute of the config name is kept
+//
+// Example:
+//
+//	vigilant.SetGlobalAttributes(vigilant.String("shard", "7"), vigilant.Bool("leader", true))
+func SetGlobalAttributes(attributes ...Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
+		return
+	}
+	client.SetGlobalAttributes(attributes...)
+}
+
+// AddGlobalAttribute adds a global attribute to the Vigilant instance, replacing the attribute with the same key
+// it can be called at any time, for example once the leader of a cluster is elected
+//
+// Example:
+//
+//	vigilant.AddGlobalAttribute(vigilant.String("deployment", "blue"))
+func AddGlobalAttribute(attribute Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
+		return
+	}
+	client.AddGlobalAttribute(attribute)
+}
+
+// RemoveGlobalAttribute removes a global attribute from the Vigilant instance
+func RemoveGlobalAttribute(key string) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
+		return
+	}
+	client.RemoveGlobalAttribute(key)
+}
+
+// GlobalAttributes returns a copy of the global attributes of the Vigilant instance
+func GlobalAttributes() map[string]string {
+	client := globalClient.Load()
+	if client == nil {
+		return nil
+	}
+	return client.GlobalAttributes()
+}
@@ -0,0 +1,113 @@
+package vigilant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer is a Vigilant server that records the batches it accepts
+// respond returns the status of each request, the batch is recorded when it is 2xx
+type testServer struct {
+	*httptest.Server
+
+	mux      sync.Mutex
+	requests int
+	batches  []*messageBatch
+	respond  func(w http.ResponseWriter, r *http.Request) int
+}
+
+// newTestServer starts a test server answering every request with the status returned by respond
+func newTestServer(t *testing.T, respond func(w http.ResponseWriter, r *http.Request) int) *testServer {
+	t.Helper()
+	s := &testServer{respond: respond}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// handle answers a request and records the batch when it is accepted
+func (s *testServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mux.Lock()
+	s.requests++
+	s.mux.Unlock()
+
+	status := http.StatusOK
+	if s.respond != nil {
+		status = s.respond(w, r)
+	}
+	if status < 200 || status >= 300 {
+		w.WriteHeader(status)
+		return
+	}
+
+	var batch messageBatch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mux.Lock()
+	s.batches = append(s.batches, &batch)
+	s.mux.Unlock()
+	w.WriteHeader(status)
+}
+
+// endpoint returns the endpoint of the server for VigilantConfig.Endpoint
+func (s *testServer) endpoint() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// requestCount returns the number of requests received
+func (s *testServer) requestCount() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.requests
+}
+
+// logCount returns the number of logs in the accepted batches
+func (s *testServer) logCount() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	count := 0
+	for _, batch := range s.batches {
+		count += len(batch.Logs)
+	}
+	return count
+}
+
+// testConfig returns a builder sending to the test server with fast retries
+func testConfig(s *testServer) *VigilantConfigBuilder {
+	return NewConfigBuilder().
+		WithName("test").
+		WithToken("tk_test").
+		WithEndpoint(s.endpoint()).
+		WithInsecure(true).
+		WithInternalLogger(discardLogger{}).
+		WithRetry(RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		})
+}
+
+// discardLogger is an InternalLogger that drops every message
+type discardLogger struct{}
+
+// Printf drops the message
+func (discardLogger) Printf(format string, args ...any) {}
+
+// waitFor polls the condition until it holds or the timeout passes
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before the timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,327 @@
+package vigilant
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+	maxErrorBodySize      = 1024
+)
+
+// StatusError is returned when the server responds to a batch with a non-2xx status code
+type StatusError struct {
+	// StatusCode is the HTTP status code returned by the server
+	StatusCode int
+
+	// Body is the beginning of the response body, it usually explains the failure
+	Body string
+
+	// retryAfter is the delay requested by the server through the Retry-After header
+	retryAfter time.Duration
+}
+
+// Error returns the string representation of the error
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("server responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("server responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether the request may succeed if it is sent again
+func (e *StatusError) Retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode == http.StatusRequestTimeout:
+		return true
+	case e.StatusCode >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+// httpTransport sends batches to the server over HTTP
+// it checks the response status and retries failed sends with jittered exponential backoff,
+// when a spool is configured, batches that still fail are written to it and replayed later
+// when a codec is configured, bodies above the minimum size are compressed once before the first attempt
+type httpTransport struct {
+	token       string
+	endpoint    string
+	client      *http.Client
+	retry       RetryConfig
+	compression CompressionConfig
+	headers     map[string]string
+	diag        *diagnostics
+
+	spool       *spool
+	failing     atomic.Bool
+	lifecycle   sync.Mutex
+	started     bool
+	stopped     bool
+	replayWake  chan struct{}
+	replayStop  chan struct{}
+	replayCtx   context.Context
+	replayAbort context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// newHTTPTransport creates a new httpTransport
+func newHTTPTransport(
+	token string,
+	endpoint string,
+	httpClient *http.Client,
+	retry RetryConfig,
+	compression CompressionConfig,
+	headers map[string]string,
+	spool *spool,
+	diag *diagnostics,
+) *httpTransport {
+	replayCtx, replayAbort := context.WithCancel(context.Background())
+	return &httpTransport{
+		token:       token,
+		endpoint:    endpoint,
+		client:      httpClient,
+		retry:       retry.withDefaults(),
+		compression: compression.withDefaults(),
+		headers:     headers,
+		diag:        diag,
+		spool:       spool,
+		replayWake:  make(chan struct{}, 1),
+		replayStop:  make(chan struct{}),
+		replayCtx:   replayCtx,
+		replayAbort: replayAbort,
+	}
+}
+
+// start starts replaying the spool, if there is one
+// only the first call has any effect, and the transport cannot be started once it is stopped
+func (t *httpTransport) start() {
+	if t.spool == nil {
+		return
+	}
+	t.lifecycle.Lock()
+	defer t.lifecycle.Unlock()
+	if t.started || t.stopped {
+		return
+	}
+	t.started = true
+	t.wg.Add(1)
+	go t.runSpoolReplay()
+}
+
+// stop stops replaying the spool, batches that were not replayed stay on disk for the next start
+// only the first call has any effect, so an exporter shared by several clients can be shut down by each of them
+func (t *httpTransport) stop() {
+	if t.spool == nil {
+		return
+	}
+	t.lifecycle.Lock()
+	if t.stopped {
+		t.lifecycle.Unlock()
+		return
+	}
+	t.stopped = true
+	t.lifecycle.Unlock()
+
+	close(t.replayStop)
+	t.replayAbort()
+	t.wg.Wait()
+}
+
+// send sends the batch to the given path
+// if the send fails with an error that is not permanent and a spool is configured, the batch is spooled,
+// this includes sends cut short by a cancelled context, so batches still pending at shutdown survive a restart
+func (t *httpTransport) send(ctx context.Context, path string, batchBytes []byte) error {
+	t.start()
+	err := t.sendWithRetry(ctx, path, batchBytes)
+	if err == nil {
+		if t.failing.Swap(false) {
+			t.wakeSpoolReplay()
+		}
+		return nil
+	}
+	t.failing.Store(true)
+	if t.spool == nil || isPermanentError(err) {
+		return err
+	}
+
+	if spoolErr := t.spool.write(path, batchBytes); spoolErr != nil {
+		return errors.Join(err, fmt.Errorf("error spooling batch: %w", spoolErr))
+	}
+
+	return fmt.Errorf("%w (batch spooled for replay)", err)
+}
+
+// runSpoolReplay replays the spool on start, periodically, and when sends recover after a failure
+func (t *httpTransport) runSpoolReplay() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.spool.replay(t.replayCtx, t.sendSpooled); err != nil && t.replayCtx.Err() == nil {
+			t.diag.reportError(fmt.Errorf("error replaying spool: %w", err))
+		}
+
+		select {
+		case <-t.replayStop:
+			return
+		case <-ticker.C:
+		case <-t.replayWake:
+		}
+	}
+}
+
+// sendSpooled sends a spooled batch, adding back the token that is left out of the segments
+func (t *httpTransport) sendSpooled(ctx context.Context, path string, batchBytes []byte) error {
+	return t.sendWithRetry(ctx, path, withSpoolToken(batchBytes, t.token))
+}
+
+// wakeSpoolReplay asks the replay goroutine to replay the spool without waiting for the ticker
+func (t *httpTransport) wakeSpoolReplay() {
+	if t.spool == nil {
+		return
+	}
+	select {
+	case t.replayWake <- struct{}{}:
+	default:
+	}
+}
+
+// sendWithRetry sends the batch to the given path, retrying retryable failures until the attempts run out
+func (t *httpTransport) sendWithRetry(ctx context.Context, path string, batchBytes []byte) error {
+	body, encoding := batchBytes, ""
+	if codec := t.compression.Codec; codec != nil && len(batchBytes) >= t.compression.MinSize {
+		buf := bytes.NewBuffer(make([]byte, 0, len(batchBytes)/4))
+		if err := codec.Compress(buf, batchBytes); err != nil {
+			return fmt.Errorf("error compressing batch: %w", err)
+		}
+		body, encoding = buf.Bytes(), codec.Encoding()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := t.sendOnce(ctx, path, body, encoding)
+		if err == nil {
+			return nil
+		}
+		if !isRetryableError(err) || attempt >= t.retry.MaxAttempts {
+			return err
+		}
+
+		countRetry(ctx)
+		timer := time.NewTimer(t.backoff(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// sendOnce sends the body a single time and checks the response status
+// the encoding is the Content-Encoding of the body, it is empty for uncompressed bodies
+func (t *httpTransport) sendOnce(ctx context.Context, path string, body []byte, encoding string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", t.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Body:       string(bytes.TrimSpace(respBody)),
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// backoff returns the delay before the next attempt
+// the delay grows exponentially with jitter, a longer Retry-After from the server is honoured,
+// and the result never exceeds the configured maximum
+func (t *httpTransport) backoff(attempt int, err error) time.Duration {
+	base := t.retry.InitialBackoff << (attempt - 1)
+	if base <= 0 || base > t.retry.MaxBackoff {
+		base = t.retry.MaxBackoff
+	}
+	delay := base/2 + rand.N(base/2+1)
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.retryAfter > delay {
+		delay = statusErr.retryAfter
+	}
+
+	return min(delay, t.retry.MaxBackoff)
+}
+
+// isRetryableError reports whether a failed send should be attempted again
+// network errors and 408, 429 and 5xx responses are retryable, other responses are permanent
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable()
+	}
+
+	return true
+}
+
+// isPermanentError reports whether a failed send can never succeed, i.e. the server rejected the batch
+// unlike isRetryableError, it is false for a cancelled context since the batch can be sent later
+func isPermanentError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && !statusErr.Retryable()
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
@@ -0,0 +1,161 @@
+package vigilant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTransport creates a transport sending to the test server with the given retry policy
+func newTestTransport(s *testServer, retry RetryConfig) *httpTransport {
+	return newHTTPTransport("tk_test", s.URL, s.Client(), retry, CompressionConfig{}, nil, nil, defaultDiagnostics)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 02 Jan 2006 15:04:05 GMT": 0,
+	} {
+		if got := parseRetryAfter(value); got != want {
+			t.Errorf("parseRetryAfter(%q): expected %v, got %v", value, want, got)
+		}
+	}
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got < 58*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q): expected about a minute, got %v", date, got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := &httpTransport{retry: RetryConfig{MaxAttempts: 10, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 100 * time.Millisecond}}
+	for attempt, bounds := range map[int][2]time.Duration{
+		1: {5 * time.Millisecond, 10 * time.Millisecond},
+		2: {10 * time.Millisecond, 20 * time.Millisecond},
+		3: {20 * time.Millisecond, 40 * time.Millisecond},
+		5: {50 * time.Millisecond, 100 * time.Millisecond},
+		9: {50 * time.Millisecond, 100 * time.Millisecond},
+	} {
+		for range 20 {
+			if delay := transport.backoff(attempt, errors.New("network")); delay < bounds[0] || delay > bounds[1] {
+				t.Fatalf("attempt %d: expected a delay between %v and %v, got %v", attempt, bounds[0], bounds[1], delay)
+			}
+		}
+	}
+
+	retryAfter := &StatusError{StatusCode: http.StatusTooManyRequests, retryAfter: 80 * time.Millisecond}
+	if delay := transport.backoff(1, retryAfter); delay != 80*time.Millisecond {
+		t.Errorf("expected the Retry-After delay, got %v", delay)
+	}
+	retryAfter.retryAfter = time.Hour
+	if delay := transport.backoff(1, retryAfter); delay != 100*time.Millisecond {
+		t.Errorf("expected the Retry-After delay to be capped, got %v", delay)
+	}
+}
+
+func TestSendWithRetry(t *testing.T) {
+	retry := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	for _, test := range []struct {
+		name     string
+		statuses []int
+		requests int
+		status   int
+	}{
+		{"success", []int{200}, 1, 0},
+		{"recovers from 503", []int{503, 502, 200}, 3, 0},
+		{"retries 429 and 408", []int{429, 408, 200}, 3, 0},
+		{"gives up after the attempts", []int{503, 503, 503, 200}, 3, 503},
+		{"does not retry 400", []int{400, 200}, 1, 400},
+		{"does not retry 401", []int{401, 200}, 1, 401},
+		{"does not retry 413", []int{413, 200}, 1, 413},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+				return test.statuses[calls.Add(1)-1]
+			})
+
+			err := newTestTransport(server, retry).sendWithRetry(context.Background(), logEndpoint, []byte(`{"logs":[]}`))
+			if requests := server.requestCount(); requests != test.requests {
+				t.Errorf("expected %d requests, got %d", test.requests, requests)
+			}
+			if test.status == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != test.status {
+				t.Fatalf("expected a StatusError with status %d, got %v", test.status, err)
+			}
+			if permanent := test.status < 500; isPermanentError(err) != permanent {
+				t.Errorf("expected permanent=%v for %v", permanent, err)
+			}
+		})
+	}
+}
+
+func TestSendWithRetryHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first atomic.Int64
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+		if calls.Add(1) == 1 {
+			first.Store(time.Now().UnixNano())
+			w.Header().Set("Retry-After", "1")
+			return http.StatusTooManyRequests
+		}
+		return http.StatusOK
+	})
+
+	transport := newTestTransport(server, RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Second})
+	if err := transport.sendWithRetry(context.Background(), logEndpoint, []byte(`{"logs":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(time.Unix(0, first.Load())); elapsed < time.Second {
+		t.Fatalf("expected the retry to wait for Retry-After, it was sent after %v", elapsed)
+	}
+}
+
+func TestSendWithRetryStopsOnCancel(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+		return http.StatusServiceUnavailable
+	})
+	transport := newTestTransport(server, RetryConfig{MaxAttempts: 100, InitialBackoff: time.Second, MaxBackoff: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := transport.sendWithRetry(ctx, logEndpoint, []byte(`{"logs":[]}`))
+	if !errors.Is(err, context.DeadlineExceeded) || isPermanentError(err) {
+		t.Fatalf("expected a cancelled send that is not permanent, got %v", err)
+	}
+	if requests := server.requestCount(); requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestRetriesCountedInStats(t *testing.T) {
+	var calls atomic.Int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+		if calls.Add(1) <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	client := NewClient(testConfig(server).Build())
+	client.LogInfo("retried")
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := client.Stats().Logs
+	if stats.Retries != 2 || stats.Sent != 1 || stats.Failed != 0 {
+		t.Fatalf("expected 2 retries and 1 sent log, got %+v", stats)
+	}
+}
@@ -0,0 +1,163 @@
+package vigilant
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ParseLevel converts a level name into a LogLevel
+// it ignores case and accepts the usual spellings, e.g. "warn", "WARNING" and "wrn" are all LEVEL_WARN
+func ParseLevel(level string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "TRACE", "TRC":
+		return LEVEL_TRACE, nil
+	case "DEBUG", "DBG":
+		return LEVEL_DEBUG, nil
+	case "INFO", "INF", "INFORMATION":
+		return LEVEL_INFO, nil
+	case "WARN", "WARNING", "WRN":
+		return LEVEL_WARN, nil
+	case "ERROR", "ERR":
+		return LEVEL_ERROR, nil
+	default:
+		return "", fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// ParseLoggerLevels parses per-logger levels written as a comma separated list of name=level pairs,
+// e.g. "db=DEBUG,http=WARN"
+func ParseLoggerLevels(spec string) (map[string]LogLevel, error) {
+	levels := make(map[string]LogLevel)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("logger level %q is not name=level", pair)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("logger %s: %w", name, err)
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// SetLevel changes the minimum level of the logs sent by the Vigilant instance
+// it can be called at any time, for example to turn on debug logs during an incident
+// the level is parsed with ParseLevel, an invalid level is reported to the error handler and ignored
+func SetLevel(level LogLevel) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
+		return
+	}
+	client.SetLevel(level)
+}
+
+// SetLoggerLevel changes the minimum level of the logs sent by the loggers with the given name
+// it takes precedence over the level set with SetLevel, an empty level removes the override
+// and an invalid level is reported to the error handler and ignored
+func SetLoggerLevel(name string, level LogLevel) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
+		return
+	}
+	client.SetLoggerLevel(name, level)
+}
+
+// SetLoggerLevels replaces every per-logger level of the Vigilant instance
+// the levels are kept as they are when one of them is invalid
+func SetLoggerLevels(levels map[string]LogLevel) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
+		return
+	}
+	client.SetLoggerLevels(levels)
+}
+
+// levelState is an immutable snapshot of the levels of an instance
+// it is replaced as a whole when a level changes, so it can be read without locking
+type levelState struct {
+	level   LogLevel
+	loggers map[string]LogLevel
+}
+
+// levelFor returns the minimum level of the logger with the given name
+// the override of the closest dotted parent is used when the name has none, e.g. "db" for "db.pool"
+func (s *levelState) levelFor(name string) LogLevel {
+	for name != "" {
+		if level, ok := s.loggers[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i == -1 {
+			break
+		}
+		name = name[:i]
+	}
+	return s.level
+}
+
+// parseConfigLevels parses the levels of the config with ParseLevel
+// an invalid level is reported and ignored, so the default level is kept and an invalid per-logger level is left out
+func (a *instance) parseConfigLevels(config *VigilantConfig) *levelState {
+	state := &levelState{
+		level:   LEVEL_TRACE,
+		loggers: make(map[string]LogLevel, len(config.LoggerLevels)),
+	}
+	if config.Level != "" {
+		if level, ok := a.parseRuntimeLevel("Level", config.Level); ok {
+			state.level = level
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.LoggerLevels)) {
+		if level, ok := a.parseRuntimeLevel(loggerLevelField(name), config.LoggerLevels[name]); ok {
+			state.loggers[name] = level
+		}
+	}
+	return state
+}
+
+// parseRuntimeLevel parses a level of the config or set at runtime, an invalid level is reported and false is returned
+func (a *instance) parseRuntimeLevel(field string, level LogLevel) (LogLevel, bool) {
+	parsed, err := ParseLevel(string(level))
+	if err != nil {
+		a.diag.reportError(&ConfigError{Field: field, Value: string(level), Reason: "level must be one of TRACE, DEBUG, INFO, WARNING or ERROR, ignoring it"})
+		return "", false
+	}
+	return parsed, true
+}
+
+// loggerLevelField returns the name of the per-logger level in a ConfigError, e.g. LoggerLevels["db"]
+func loggerLevelField(name string) string {
+	return fmt.Sprintf("LoggerLevels[%q]", name)
+}
+
+// levelEnabled reports whether a log at the given level from the named logger is sent
+// the name is empty for logs that do not come from a named logger
+func (a *instance) levelEnabled(level LogLevel, name string) bool {
+	return isLevelEnabled(level, a.levels.Load().levelFor(name))
+}
+
+// updateLevels replaces the level snapshot with a modified copy
+func (a *instance) updateLevels(update func(state *levelState)) {
+	a.levelsMux.Lock()
+	defer a.levelsMux.Unlock()
+
+	current := a.levels.Load()
+	next := &levelState{
+		level:   current.level,
+		loggers: maps.Clone(current.loggers),
+	}
+	if next.loggers == nil {
+		next.loggers = make(map[string]LogLevel)
+	}
+	update(next)
+	a.levels.Store(next)
+}
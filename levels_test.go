@@ -0,0 +1,138 @@
+package vigilant
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestLoggerLevelFallsBackToParent(t *testing.T) {
+	client := NewClient(NewConfigBuilder().WithNoop(true).WithLevel(LEVEL_WARN).Build())
+	defer client.Shutdown()
+	client.SetLoggerLevel("db", LEVEL_DEBUG)
+	client.SetLoggerLevel("db.pool.stats", LEVEL_ERROR)
+
+	for _, test := range []struct {
+		name  string
+		level LogLevel
+		want  bool
+	}{
+		{"", LEVEL_INFO, false},
+		{"", LEVEL_WARN, true},
+		{"http", LEVEL_INFO, false},
+		{"db", LEVEL_DEBUG, true},
+		{"db", LEVEL_TRACE, false},
+		{"db.pool", LEVEL_DEBUG, true},
+		{"db.pool.stats", LEVEL_WARN, false},
+		{"db.pool.stats.idle", LEVEL_ERROR, true},
+		{"dbx", LEVEL_DEBUG, false},
+	} {
+		if got := client.instance.levelEnabled(test.level, test.name); got != test.want {
+			t.Errorf("logger %q at %s: expected enabled=%v, got %v", test.name, test.level, test.want, got)
+		}
+	}
+
+	client.SetLoggerLevel("db", "")
+	if client.instance.levelEnabled(LEVEL_DEBUG, "db.pool") {
+		t.Error("expected db.pool to use the global level once the override of db is removed")
+	}
+}
+
+func TestSetLevelIgnoresInvalidLevels(t *testing.T) {
+	var mux sync.Mutex
+	var errs []error
+	client := NewClient(NewConfigBuilder().WithNoop(true).WithLevel(LEVEL_WARN).WithErrorHandler(func(err error) {
+		mux.Lock()
+		errs = append(errs, err)
+		mux.Unlock()
+	}).Build())
+	defer client.Shutdown()
+	client.SetLoggerLevel("db", LEVEL_INFO)
+
+	client.SetLevel("VERBOSE")
+	client.SetLoggerLevel("db", "LOUD")
+	client.SetLoggerLevels(map[string]LogLevel{"http": LEVEL_ERROR, "db.pool": "VERBOSE"})
+
+	if level := client.Level(); level != LEVEL_WARN {
+		t.Errorf("expected the level to be kept, got %q", level)
+	}
+	if client.instance.levelEnabled(LEVEL_DEBUG, "") {
+		t.Error("expected debug logs to stay disabled")
+	}
+	if levels := client.instance.levels.Load().loggers; len(levels) != 1 || levels["db"] != LEVEL_INFO {
+		t.Errorf("expected the logger levels to be kept, got %v", levels)
+	}
+
+	mux.Lock()
+	var fields []string
+	for _, err := range errs {
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected a ConfigError, got %v", err)
+		}
+		fields = append(fields, configErrorFields(err)...)
+	}
+	mux.Unlock()
+	want := []string{"Level", `LoggerLevels["db"]`, `LoggerLevels["db.pool"]`}
+	if len(fields) != len(want) {
+		t.Fatalf("expected errors for %v, got %v", want, fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("expected errors for %v, got %v", want, fields)
+		}
+	}
+
+	client.SetLevel("debug")
+	if level := client.Level(); level != LEVEL_DEBUG {
+		t.Errorf("expected lowercase levels to be parsed, got %q", level)
+	}
+}
+
+func TestConfigLevelsAreParsed(t *testing.T) {
+	var mux sync.Mutex
+	var errs []error
+	config := NewConfigBuilder().WithNoop(true).WithErrorHandler(func(err error) {
+		mux.Lock()
+		errs = append(errs, err)
+		mux.Unlock()
+	}).Build()
+	config.Level = "warning"
+	config.LoggerLevels = map[string]LogLevel{"db": "debug", "http": "LOUD"}
+	if err := config.Validate(); err == nil {
+		t.Error("expected Validate to reject the invalid logger level")
+	}
+
+	client := NewClient(config)
+	defer client.Shutdown()
+
+	if level := client.Level(); level != LEVEL_WARN {
+		t.Errorf("expected the level to be parsed, got %q", level)
+	}
+	if client.instance.levelEnabled(LEVEL_INFO, "") {
+		t.Error("expected info logs to be disabled")
+	}
+	if !client.instance.levelEnabled(LEVEL_DEBUG, "db") {
+		t.Error("expected debug logs of db to be enabled")
+	}
+	if client.instance.levelEnabled(LEVEL_INFO, "http") {
+		t.Error("expected the invalid level of http to be ignored")
+	}
+
+	config.Level = "VERBOSE"
+	config.LoggerLevels = nil
+	other := NewClient(config)
+	defer other.Shutdown()
+	if level := other.Level(); level != LEVEL_TRACE {
+		t.Errorf("expected the default level to be kept, got %q", level)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, configErrorFields(err)...)
+	}
+	if len(fields) != 2 || fields[0] != `LoggerLevels["http"]` || fields[1] != "Level" {
+		t.Fatalf("expected errors for the invalid levels, got %v", fields)
+	}
+}
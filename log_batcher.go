@@ -1,47 +1,37 @@
 package vigilant
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"context"
+	"errors"
 	"sync"
 	"time"
 )
 
-const (
-	logEndpoint     = "/api/message"
-	maxLogBatchSize = 100
-)
-
 // logBatcher is a struct that contains the queues for the logs
-// it also contains the http client and the wait group
-// when a batch is ready, the logBatcher will send it to the server
+// it also contains the exporter and the wait group
+// when a batch is ready, the logBatcher will export it
 type logBatcher struct {
-	token    string
-	endpoint string
+	logQueue *queue[*LogMessage]
 
-	logQueue chan *logMessage
+	exporter Exporter
+	batch    BatchConfig
 
-	client *http.Client
-
-	stopped   bool
-	batchStop chan struct{}
-	wg        sync.WaitGroup
+	*pipeline
+	wg sync.WaitGroup
 }
 
 // newLogBatcher creates a new logBatcher
 func newLogBatcher(
-	token string,
-	endpoint string,
-	httpClient *http.Client,
+	exporter Exporter,
+	queueConfig QueueConfig,
+	batchConfig BatchConfig,
+	diag *diagnostics,
 ) *logBatcher {
 	return &logBatcher{
-		token:     token,
-		endpoint:  endpoint,
-		logQueue:  make(chan *logMessage, 1000),
-		batchStop: make(chan struct{}),
-		client:    httpClient,
+		logQueue: newQueue[*LogMessage](queueConfig),
+		exporter: exporter,
+		batch:    batchConfig.withDefaults(),
+		pipeline: newPipeline(diag),
 	}
 }
 
@@ -51,124 +41,76 @@ func (b *logBatcher) start() {
 	go b.runLogBatcher()
 }
 
-// addLog adds a log to the batcher's queue
-func (b *logBatcher) addLog(message *logMessage) {
-	if message == nil || b.stopped {
+// addLog adds a log to the batcher's queue, it is a noop once the batcher is stopped
+func (b *logBatcher) addLog(message *LogMessage) {
+	if message == nil {
 		return
 	}
-	b.logQueue <- message
+	b.logQueue.push(message)
 }
 
-// stop stops the batcher and processes remaining logs
-func (b *logBatcher) stop() {
-	b.stopped = true
-	close(b.batchStop)
-	b.wg.Wait()
-
-	close(b.logQueue)
-	b.processAfterShutdown()
+// stop stops the batcher, the queue is closed and the batcher sends the remaining logs before returning
+// when the context is done first, the remaining sends are cancelled
+func (b *logBatcher) stop(ctx context.Context) error {
+	return b.pipeline.stop(ctx, b.logQueue.close, b.wg.Wait)
 }
 
 // runLogBatcher runs the log batcher
 func (b *logBatcher) runLogBatcher() {
 	defer b.wg.Done()
+	defer close(b.exited)
 
-	ticker := time.NewTicker(100 * time.Millisecond)
+	ticker := time.NewTicker(b.batch.Interval)
 	defer ticker.Stop()
 
-	var logs []*logMessage
+	var logs []*LogMessage
 	for {
 		select {
-		case <-b.batchStop:
-			if len(logs) > 0 {
-				if err := b.sendLogBatch(logs); err != nil {
-					fmt.Printf("error sending final log batch: %v\n", err)
-				}
+		case msg, ok := <-b.logQueue.items:
+			if !ok {
+				b.reportError(b.sendLogBatches(b.sendCtx, logs))
+				return
 			}
-			return
-		case msg := <-b.logQueue:
 			if msg == nil {
 				continue
 			}
 			logs = append(logs, msg)
-			if len(logs) >= maxLogBatchSize {
-				if err := b.sendLogBatch(logs); err != nil {
-					fmt.Printf("error sending log batch: %v\n", err)
-				}
+			if len(logs) >= b.batch.MaxSize {
+				b.reportError(b.sendLogBatches(b.sendCtx, logs))
 				logs = nil
 			}
+		case req := <-b.flushes:
+			logs = append(logs, drainQueue(b.logQueue)...)
+			req.done <- b.sendLogBatches(req.ctx, logs)
+			logs = nil
 		case <-ticker.C:
 			if len(logs) > 0 {
-				if err := b.sendLogBatch(logs); err != nil {
-					fmt.Printf("error sending log batch: %v\n", err)
-				}
+				b.reportError(b.sendLogBatches(b.sendCtx, logs))
 				logs = nil
 			}
 		}
 	}
 }
 
-// processAfterShutdown processes any remaining logs in the queue after shutdown.
-func (b *logBatcher) processAfterShutdown() {
-	var logs []*logMessage
-	for msg := range b.logQueue {
-		if msg == nil {
-			continue
-		}
-		logs = append(logs, msg)
-		if len(logs) >= 100 {
-			if err := b.sendLogBatch(logs); err != nil {
-				fmt.Printf("error sending shutdown log batch: %v\n", err)
-			}
-			logs = nil
-		}
-	}
-	if len(logs) > 0 {
-		if err := b.sendLogBatch(logs); err != nil {
-			fmt.Printf("error sending final shutdown log batch: %v\n", err)
+// sendLogBatches exports the logs in batches of at most the batch size
+func (b *logBatcher) sendLogBatches(ctx context.Context, logs []*LogMessage) error {
+	var errs []error
+	for len(logs) > 0 {
+		n := min(len(logs), b.batch.MaxSize)
+		if err := b.sendLogBatch(ctx, logs[:n]); err != nil {
+			errs = append(errs, &SendError{Pipeline: PipelineLogs, Items: n, Err: err})
 		}
+		logs = logs[n:]
 	}
+	return errors.Join(errs...)
 }
 
-// sendLogBatch sends a log batch to the server
-func (b *logBatcher) sendLogBatch(logs []*logMessage) error {
+// sendLogBatch exports a log batch
+func (b *logBatcher) sendLogBatch(ctx context.Context, logs []*LogMessage) error {
 	if len(logs) == 0 {
 		return nil
 	}
-
-	batch := &messageBatch{
-		Token: b.token,
-		Logs:  logs,
-	}
-
-	batchBytes, err := json.Marshal(batch)
-	if err != nil {
-		return err
-	}
-
-	err = b.sendBatch(batchBytes)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// sendBatch sends a batch to the server
-func (b *logBatcher) sendBatch(batchBytes []byte) error {
-	req, err := http.NewRequest("POST", b.endpoint+logEndpoint, bytes.NewBuffer(batchBytes))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+b.token)
-
-	resp, err := b.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	return nil
+	return b.sends.export(ctx, len(logs), func(ctx context.Context) error {
+		return b.exporter.ExportLogs(ctx, logs)
+	})
 }
@@ -0,0 +1,293 @@
+package vigilant
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+const (
+	loggerAttribute = "logger"
+)
+
+// Logger is a logger with a set of bound attributes and an optional name
+// the attributes are converted once when the logger is created and added to every log it captures,
+// the name selects the per-logger level set with SetLoggerLevel
+type Logger struct {
+	client *Client
+	name   string
+	attrs  map[string]string
+}
+
+// With returns a logger that adds the given attributes to every log
+//
+// Use this function when several logs share the same attributes, such as a component or tenant.
+//
+// Example:
+//
+//	logger := vigilant.With(vigilant.String("component", "billing"))
+//	logger.LogInfo("Invoice created")
+func With(attributes ...Attribute) *Logger {
+	return &Logger{
+		attrs: attributesToMap(attributes...),
+	}
+}
+
+// With returns a logger that sends logs through the client and adds the given attributes to every log
+func (c *Client) With(attributes ...Attribute) *Logger {
+	return &Logger{
+		client: c,
+		attrs:  attributesToMap(attributes...),
+	}
+}
+
+// With returns a child logger that adds the given attributes to the ones already bound
+func (l *Logger) With(attributes ...Attribute) *Logger {
+	attrs := maps.Clone(l.attrs)
+	maps.Copy(attrs, attributesToMap(attributes...))
+	return &Logger{
+		client: l.client,
+		name:   l.name,
+		attrs:  attrs,
+	}
+}
+
+// Named returns a logger with the given name, its logs have a "logger" attribute set to the name
+//
+// The level of a named logger can be changed at runtime with SetLoggerLevel.
+//
+// Example:
+//
+//	logger := vigilant.Named("db")
+//	vigilant.SetLoggerLevel("db", vigilant.LEVEL_DEBUG)
+//	logger.LogDebug("Query planned")
+func Named(name string) *Logger {
+	return &Logger{
+		name:  name,
+		attrs: map[string]string{loggerAttribute: name},
+	}
+}
+
+// Named returns a logger with the given name that sends logs through the client
+func (c *Client) Named(name string) *Logger {
+	return &Logger{
+		client: c,
+		name:   name,
+		attrs:  map[string]string{loggerAttribute: name},
+	}
+}
+
+// Named returns a child logger whose name is the name of the parent and the given name joined with a dot,
+// e.g. "db.pool", a child without its own level uses the level of the closest parent
+func (l *Logger) Named(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	attrs := maps.Clone(l.attrs)
+	if attrs == nil {
+		attrs = make(map[string]string)
+	}
+	attrs[loggerAttribute] = name
+	return &Logger{
+		client: l.client,
+		name:   name,
+		attrs:  attrs,
+	}
+}
+
+// ----------------------- //
+// --- General Logging --- //
+// ----------------------- //
+
+// Log logs a message at the given level
+func (l *Logger) Log(level LogLevel, message string) {
+	l.log(level, message, nil)
+}
+
+// LogError logs an error message
+func (l *Logger) LogError(message string) {
+	l.log(LEVEL_ERROR, message, nil)
+}
+
+// LogWarn logs a warning message
+func (l *Logger) LogWarn(message string) {
+	l.log(LEVEL_WARN, message, nil)
+}
+
+// LogInfo logs an info message
+func (l *Logger) LogInfo(message string) {
+	l.log(LEVEL_INFO, message, nil)
+}
+
+// LogDebug logs a debug message
+func (l *Logger) LogDebug(message string) {
+	l.log(LEVEL_DEBUG, message, nil)
+}
+
+// LogTrace logs a trace message
+func (l *Logger) LogTrace(message string) {
+	l.log(LEVEL_TRACE, message, nil)
+}
+
+// ------------------------- //
+// --- Formatted Logging --- //
+// ------------------------- //
+
+// LogErrorf logs an error message with a formatted message
+func (l *Logger) LogErrorf(template string, args ...any) {
+	l.log(LEVEL_ERROR, fmt.Sprintf(template, args...), nil)
+}
+
+// LogWarnf logs a warning message with a formatted message
+func (l *Logger) LogWarnf(template string, args ...any) {
+	l.log(LEVEL_WARN, fmt.Sprintf(template, args...), nil)
+}
+
+// LogInfof logs an info message with a formatted message
+func (l *Logger) LogInfof(template string, args ...any) {
+	l.log(LEVEL_INFO, fmt.Sprintf(template, args...), nil)
+}
+
+// LogDebugf logs a debug message with a formatted message
+func (l *Logger) LogDebugf(template string, args ...any) {
+	l.log(LEVEL_DEBUG, fmt.Sprintf(template, args...), nil)
+}
+
+// LogTracef logs a trace message with a formatted message
+func (l *Logger) LogTracef(template string, args ...any) {
+	l.log(LEVEL_TRACE, fmt.Sprintf(template, args...), nil)
+}
+
+// ------------------------------- //
+// --- Typed Attribute Logging --- //
+// ------------------------------- //
+
+// LogErrort logs an error message with typed attributes
+func (l *Logger) LogErrort(message string, attributes ...Attribute) {
+	l.log(LEVEL_ERROR, message, attributesToMap(attributes...))
+}
+
+// LogWarnt logs a warning message with typed attributes
+func (l *Logger) LogWarnt(message string, attributes ...Attribute) {
+	l.log(LEVEL_WARN, message, attributesToMap(attributes...))
+}
+
+// LogInfot logs an info message with typed attributes
+func (l *Logger) LogInfot(message string, attributes ...Attribute) {
+	l.log(LEVEL_INFO, message, attributesToMap(attributes...))
+}
+
+// LogDebugt logs a debug message with typed attributes
+func (l *Logger) LogDebugt(message string, attributes ...Attribute) {
+	l.log(LEVEL_DEBUG, message, attributesToMap(attributes...))
+}
+
+// LogTracet logs a trace message with typed attributes
+func (l *Logger) LogTracet(message string, attributes ...Attribute) {
+	l.log(LEVEL_TRACE, message, attributesToMap(attributes...))
+}
+
+// -------------------------------- //
+// --- Free-form Attribute Logs --- //
+// -------------------------------- //
+
+// LogErrorw logs an error message with key-value attributes
+func (l *Logger) LogErrorw(message string, keyVals ...any) {
+	l.logw(LEVEL_ERROR, message, keyVals...)
+}
+
+// LogWarnw logs a warning message with key-value attributes
+func (l *Logger) LogWarnw(message string, keyVals ...any) {
+	l.logw(LEVEL_WARN, message, keyVals...)
+}
+
+// LogInfow logs an info message with key-value attributes
+func (l *Logger) LogInfow(message string, keyVals ...any) {
+	l.logw(LEVEL_INFO, message, keyVals...)
+}
+
+// LogDebugw logs a debug message with key-value attributes
+func (l *Logger) LogDebugw(message string, keyVals ...any) {
+	l.logw(LEVEL_DEBUG, message, keyVals...)
+}
+
+// ----------------------- //
+// --- Context Logging --- //
+// ----------------------- //
+
+// LogCtx logs a message at the given level with the context attributes
+func (l *Logger) LogCtx(ctx context.Context, level LogLevel, message string, attributes ...Attribute) {
+	l.log(level, message, contextAttributes(ctx, attributes...))
+}
+
+// LogErrorCtx logs an error message with the context attributes and typed attributes
+func (l *Logger) LogErrorCtx(ctx context.Context, message string, attributes ...Attribute) {
+	l.log(LEVEL_ERROR, message, contextAttributes(ctx, attributes...))
+}
+
+// LogWarnCtx logs a warning message with the context attributes and typed attributes
+func (l *Logger) LogWarnCtx(ctx context.Context, message string, attributes ...Attribute) {
+	l.log(LEVEL_WARN, message, contextAttributes(ctx, attributes...))
+}
+
+// LogInfoCtx logs an info message with the context attributes and typed attributes
+func (l *Logger) LogInfoCtx(ctx context.Context, message string, attributes ...Attribute) {
+	l.log(LEVEL_INFO, message, contextAttributes(ctx, attributes...))
+}
+
+// LogDebugCtx logs a debug message with the context attributes and typed attributes
+func (l *Logger) LogDebugCtx(ctx context.Context, message string, attributes ...Attribute) {
+	l.log(LEVEL_DEBUG, message, contextAttributes(ctx, attributes...))
+}
+
+// LogTraceCtx logs a trace message with the context attributes and typed attributes
+func (l *Logger) LogTraceCtx(ctx context.Context, message string, attributes ...Attribute) {
+	l.log(LEVEL_TRACE, message, contextAttributes(ctx, attributes...))
+}
+
+// log merges the bound attributes with the given attributes and logs the message
+// the given attributes take precedence over the bound attributes, when there are none
+// the bound map is used as is since createLogMessage copies it
+func (l *Logger) log(level LogLevel, message string, attrs map[string]string) {
+	client := l.getClient()
+	if client == nil {
+		gateNilGlobalInstance(nil)
+		return
+	}
+
+	if len(attrs) == 0 {
+		client.logNamed(l.name, level, message, l.attrs)
+		return
+	}
+
+	merged := maps.Clone(l.attrs)
+	maps.Copy(merged, attrs)
+	client.logNamed(l.name, level, message, merged)
+}
+
+// logw converts the key-value pairs into attributes and logs the message
+func (l *Logger) logw(level LogLevel, message string, keyVals ...any) {
+	attrs, err := keyValsToMap(keyVals...)
+	if err != nil {
+		l.diagnostics().reportError(&CaptureError{Pipeline: PipelineLogs, Err: err})
+		return
+	}
+
+	l.log(level, message, attrs)
+}
+
+// diagnostics returns the diagnostics of the logger's client, falling back to those of the package
+func (l *Logger) diagnostics() *diagnostics {
+	if client := l.getClient(); client != nil {
+		return client.instance.diag
+	}
+	return packageDiagnostics()
+}
+
+// getClient returns the logger's client, falling back to the default client
+func (l *Logger) getClient() *Client {
+	if l.client != nil {
+		return l.client
+	}
+	return globalClient.Load()
+}
@@ -0,0 +1,55 @@
+package vigilant
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestLoggerWithChainsAttributes(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).WithLevel(LEVEL_INFO).Build())
+	client.SetLoggerLevel("billing", LEVEL_DEBUG)
+
+	parent := client.With(String("component", "billing"), String("tenant", "t1"))
+	child := parent.With(String("tenant", "t2"), Int("shard", 3))
+	named := child.Named("billing").With(String("step", "charge"))
+
+	parent.LogInfo("parent")
+	child.LogInfof("child %d", 1)
+	child.LogWarnt("child typed", String("tenant", "call"))
+	child.LogErrorw("child kv", "attempt", 2)
+	named.LogDebug("named")
+	parent.LogDebug("parent debug")
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mux.Lock()
+	defer server.mux.Unlock()
+	logs := make(map[string]map[string]string)
+	for _, batch := range server.batches {
+		for _, log := range batch.Logs {
+			delete(log.Attributes, "service")
+			logs[log.Body] = log.Attributes
+		}
+	}
+
+	want := map[string]map[string]string{
+		"parent":      {"component": "billing", "tenant": "t1"},
+		"child 1":     {"component": "billing", "tenant": "t2", "shard": "3"},
+		"child typed": {"component": "billing", "tenant": "call", "shard": "3"},
+		"child kv":    {"component": "billing", "tenant": "t2", "shard": "3", "attempt": "2"},
+		"named":       {"component": "billing", "tenant": "t2", "shard": "3", "logger": "billing", "step": "charge"},
+	}
+	if len(logs) != len(want) {
+		t.Errorf("expected the logs %v, got %v", want, logs)
+	}
+	for body, attrs := range want {
+		if got := logs[body]; !maps.Equal(got, attrs) {
+			t.Errorf("log %q: expected %v, got %v", body, attrs, got)
+		}
+	}
+	if attrs := parent.attrs; !maps.Equal(attrs, map[string]string{"component": "billing", "tenant": "t1"}) {
+		t.Errorf("expected the children to leave the attributes of the parent as they were, got %v", attrs)
+	}
+}
@@ -1,6 +1,7 @@
 package vigilant
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -19,16 +20,12 @@ import (
 //
 //	Log(LEVEL_INFO, "Hello, world!")
 func Log(level LogLevel, message string) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(level, message, nil)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.Log(level, message)
 }
 
 // LogError logs an error at the given level
@@ -39,16 +36,12 @@ func Log(level LogLevel, message string) {
 //
 //	LogError("Failed to write to file")
 func LogError(message string) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	log := createLogMessage(LEVEL_ERROR, message, nil)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogError(message)
 }
 
 // LogWarn logs a warning at the given level
@@ -59,16 +52,12 @@ func LogError(message string) {
 //
 //	LogWarn("Failed to write to file")
 func LogWarn(message string) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_WARN, message, nil)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.LogWarn(message)
 }
 
 // LogInfo logs an info message at the given level
@@ -79,16 +68,12 @@ func LogWarn(message string) {
 //
 //	LogInfo("Hello, world!")
 func LogInfo(message string) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	log := createLogMessage(LEVEL_INFO, message, nil)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogInfo(message)
 }
 
 // LogDebug logs a debug message at the given level
@@ -99,16 +84,12 @@ func LogInfo(message string) {
 //
 //	LogDebug("Hello, world!")
 func LogDebug(message string) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	log := createLogMessage(LEVEL_DEBUG, message, nil)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogDebug(message)
 }
 
 // LogTrace logs a trace message at the given level
@@ -119,16 +100,12 @@ func LogDebug(message string) {
 //
 //	LogTrace("Hello, world!")
 func LogTrace(message string) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_TRACE, message, nil)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.LogTrace(message)
 }
 
 // ------------------------- //
@@ -142,16 +119,12 @@ func LogTrace(message string) {
 // Example:
 // LogErrorf("Failed to %s", "do something")
 func LogErrorf(template string, args ...any) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_ERROR, fmt.Sprintf(template, args...), nil)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.LogErrorf(template, args...)
 }
 
 // LogWarnf logs a warning at the given level
@@ -162,16 +135,12 @@ func LogErrorf(template string, args ...any) {
 //
 //	LogWarnf("Failed to %s", "do something")
 func LogWarnf(template string, args ...any) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	log := createLogMessage(LEVEL_WARN, fmt.Sprintf(template, args...), nil)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogWarnf(template, args...)
 }
 
 // LogInfof logs an info message at the given level
@@ -182,16 +151,12 @@ func LogWarnf(template string, args ...any) {
 //
 //	LogInfof("Failed to %s", "do something")
 func LogInfof(template string, args ...any) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_INFO, fmt.Sprintf(template, args...), nil)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.LogInfof(template, args...)
 }
 
 // LogDebugf logs a debug message at the given level
@@ -202,16 +167,12 @@ func LogInfof(template string, args ...any) {
 //
 //	LogDebugf("Failed to %s", "do something")
 func LogDebugf(template string, args ...any) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_DEBUG, fmt.Sprintf(template, args...), nil)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.LogDebugf(template, args...)
 }
 
 // LogTracef logs a trace message at the given level
@@ -222,16 +183,12 @@ func LogDebugf(template string, args ...any) {
 //
 //	LogTracef("Failed to %s", "do something")
 func LogTracef(template string, args ...any) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	log := createLogMessage(LEVEL_TRACE, fmt.Sprintf(template, args...), nil)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogTracef(template, args...)
 }
 
 // ------------------------------- //
@@ -246,18 +203,12 @@ func LogTracef(template string, args ...any) {
 //
 //	LogErrort("Failed to write to file", "file", "example.txt", "error", "some error")
 func LogErrort(message string, attributes ...Attribute) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	attrs := attributesToMap(attributes...)
-
-	log := createLogMessage(LEVEL_ERROR, message, attrs)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogErrort(message, attributes...)
 }
 
 // LogWarnt logs a warning at the given level with typed attributes
@@ -268,18 +219,12 @@ func LogErrort(message string, attributes ...Attribute) {
 //
 //	LogWarnt("Failed to write to file", "file", "example.txt", "error", "some error")
 func LogWarnt(message string, attributes ...Attribute) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	attrs := attributesToMap(attributes...)
-
-	log := createLogMessage(LEVEL_WARN, message, attrs)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.LogWarnt(message, attributes...)
 }
 
 // LogInfot logs an info message at the given level with typed attributes
@@ -290,18 +235,12 @@ func LogWarnt(message string, attributes ...Attribute) {
 //
 //	LogInfot("Failed to write to file", "file", "example.txt", "error", "some error")
 func LogInfot(message string, attributes ...Attribute) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	attrs := attributesToMap(attributes...)
-
-	log := createLogMessage(LEVEL_INFO, message, attrs)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogInfot(message, attributes...)
 }
 
 // LogDebugt logs a debug message at the given level with typed attributes
@@ -312,18 +251,12 @@ func LogInfot(message string, attributes ...Attribute) {
 //
 //	LogDebugt("Failed to write to file", "file", "example.txt", "error", "some error")
 func LogDebugt(message string, attributes ...Attribute) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	attrs := attributesToMap(attributes...)
-
-	log := createLogMessage(LEVEL_DEBUG, message, attrs)
-	if log == nil {
-		return
-	}
-
-	globalInstance.captureLog(log)
+	client.LogDebugt(message, attributes...)
 }
 
 // LogTracet logs a trace message at the given level with typed attributes
@@ -334,18 +267,12 @@ func LogDebugt(message string, attributes ...Attribute) {
 //
 //	LogTracet("Failed to write to file", "file", "example.txt", "error", "some error")
 func LogTracet(message string, attributes ...Attribute) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	attrs := attributesToMap(attributes...)
-
-	log := createLogMessage(LEVEL_TRACE, message, attrs)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogTracet(message, attributes...)
 }
 
 // -------------------------------- //
@@ -360,22 +287,12 @@ func LogTracet(message string, attributes ...Attribute) {
 //
 //	LogErrorw("Failed to write to file", "file", "example.txt", "error", "some error")
 func LogErrorw(message string, keyVals ...any) {
-	if gateNilGlobalInstance() {
-		return
-	}
-
-	attrs, err := keyValsToMap(keyVals)
-	if err != nil {
-		fmt.Printf("error formatting attributes: %v\n", err)
-		return
-	}
-
-	log := createLogMessage(LEVEL_ERROR, message, attrs)
-	if log == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogErrorw(message, keyVals...)
 }
 
 // LogWarnw logs a warning at the given level with key-value attributes
@@ -386,74 +303,144 @@ func LogErrorw(message string, keyVals ...any) {
 //
 //	LogWarnw("Database query too long", "query", "SELECT * FROM users", "duration", "100ms")
 func LogWarnw(message string, keyVals ...any) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	attrs, err := keyValsToMap(keyVals)
-	if err != nil {
-		fmt.Printf("error formatting attributes: %v\n", err)
+	client.LogWarnw(message, keyVals...)
+}
+
+// LogInfow logs an info message at the given level with key-value attributes
+//
+// Use this function when you want to log an info message with key-value attributes.
+//
+// Example:
+//
+//	LogInfow("User signup request", "email", "test@example.com")
+func LogInfow(message string, keyVals ...any) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_WARN, message, attrs)
-	if log == nil {
+	client.LogInfow(message, keyVals...)
+}
+
+// LogDebugw logs a debug message at the given level with key-value attributes
+//
+// Use this function when you want to log a debug message with key-value attributes.
+//
+// Example:
+//
+//	LogDebugw("Timer tick", "time", "100ms")
+func LogDebugw(message string, keyVals ...any) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogDebugw(message, keyVals...)
 }
 
-// LogInfow logs an info message at the given level with key-value attributes
+// ----------------------- //
+// --- Context Logging --- //
+// ----------------------- //
+
+// LogCtx logs a message at the given level with the attributes stored in the context
 //
-// Use this function when you want to log an info message with key-value attributes.
+// Use this function when you want to log a message with request-scoped attributes.
 //
 // Example:
 //
-//	LogInfow("User signup request", "email", "test@example.com")
-func LogInfow(message string, keyVals ...any) {
-	if gateNilGlobalInstance() {
+//	LogCtx(ctx, LEVEL_INFO, "Hello, world!")
+func LogCtx(ctx context.Context, level LogLevel, message string, attributes ...Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	attrs, err := keyValsToMap(keyVals)
-	if err != nil {
-		fmt.Printf("error formatting attributes: %v\n", err)
+	client.LogCtx(ctx, level, message, attributes...)
+}
+
+// LogErrorCtx logs an error with the attributes stored in the context
+//
+// Use this function when you want to log an error with request-scoped attributes.
+//
+// Example:
+//
+//	LogErrorCtx(ctx, "Failed to charge card", vigilant.String("card", "visa"))
+func LogErrorCtx(ctx context.Context, message string, attributes ...Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_INFO, message, attrs)
-	if log == nil {
+	client.LogErrorCtx(ctx, message, attributes...)
+}
+
+// LogWarnCtx logs a warning with the attributes stored in the context
+//
+// Use this function when you want to log a warning with request-scoped attributes.
+//
+// Example:
+//
+//	LogWarnCtx(ctx, "Slow query", vigilant.Int("duration_ms", 1200))
+func LogWarnCtx(ctx context.Context, message string, attributes ...Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogWarnCtx(ctx, message, attributes...)
 }
 
-// LogDebugw logs a debug message at the given level with key-value attributes
+// LogInfoCtx logs an info message with the attributes stored in the context
 //
-// Use this function when you want to log a debug message with key-value attributes.
+// Use this function when you want to log an info message with request-scoped attributes.
 //
 // Example:
 //
-//	LogDebugw("Timer tick", "time", "100ms")
-func LogDebugw(message string, keyVals ...any) {
-	if gateNilGlobalInstance() {
+//	LogInfoCtx(ctx, "User signed up", vigilant.String("plan", "pro"))
+func LogInfoCtx(ctx context.Context, message string, attributes ...Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	attrs, err := keyValsToMap(keyVals)
-	if err != nil {
-		fmt.Printf("error formatting attributes: %v\n", err)
+	client.LogInfoCtx(ctx, message, attributes...)
+}
+
+// LogDebugCtx logs a debug message with the attributes stored in the context
+//
+// Use this function when you want to log a debug message with request-scoped attributes.
+//
+// Example:
+//
+//	LogDebugCtx(ctx, "Cache miss", vigilant.String("key", "user:123"))
+func LogDebugCtx(ctx context.Context, message string, attributes ...Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	log := createLogMessage(LEVEL_DEBUG, message, attrs)
-	if log == nil {
+	client.LogDebugCtx(ctx, message, attributes...)
+}
+
+// LogTraceCtx logs a trace message with the attributes stored in the context
+//
+// Use this function when you want to log a trace message with request-scoped attributes.
+//
+// Example:
+//
+//	LogTraceCtx(ctx, "Entering handler")
+func LogTraceCtx(ctx context.Context, message string, attributes ...Attribute) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureLog(log)
+	client.LogTraceCtx(ctx, message, attributes...)
 }
 
 // writeLogPassthrough writes a log message to Vigilant
@@ -1,46 +1,37 @@
 package vigilant
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"context"
+	"errors"
 	"sync"
 	"time"
 )
 
-const (
-	maxMetricBatchSize = 100
-)
-
 // metricBatcher is a struct that contains the queues for the metrics
-// it also contains the http client and the wait group
-// when a batch is ready, the metricBatcher will send it to the server
+// it also contains the exporter and the wait group
+// when a batch is ready, the metricBatcher will export it
 type metricBatcher struct {
-	token    string
-	endpoint string
+	metricQueue *queue[*MetricMessage]
 
-	metricQueue chan *metricMessage
+	exporter Exporter
+	batch    BatchConfig
 
-	client *http.Client
-
-	stopped   bool
-	batchStop chan struct{}
-	wg        sync.WaitGroup
+	*pipeline
+	wg sync.WaitGroup
 }
 
 // newMetricBatcher creates a new metricBatcher
 func newMetricBatcher(
-	token string,
-	endpoint string,
-	httpClient *http.Client,
+	exporter Exporter,
+	queueConfig QueueConfig,
+	batchConfig BatchConfig,
+	diag *diagnostics,
 ) *metricBatcher {
 	return &metricBatcher{
-		token:       token,
-		endpoint:    endpoint,
-		metricQueue: make(chan *metricMessage, 1000),
-		batchStop:   make(chan struct{}),
-		client:      httpClient,
+		metricQueue: newQueue[*MetricMessage](queueConfig),
+		exporter:    exporter,
+		batch:       batchConfig.withDefaults(),
+		pipeline:    newPipeline(diag),
 	}
 }
 
@@ -50,124 +41,76 @@ func (b *metricBatcher) start() {
 	go b.runMetricBatcher()
 }
 
-// addMetric adds a metric to the batcher's queue
-func (b *metricBatcher) addMetric(message *metricMessage) {
-	if message == nil || b.stopped {
+// addMetric adds a metric to the batcher's queue, it is a noop once the batcher is stopped
+func (b *metricBatcher) addMetric(message *MetricMessage) {
+	if message == nil {
 		return
 	}
-	b.metricQueue <- message
+	b.metricQueue.push(message)
 }
 
-// stop stops the batcher and processes remaining metrics
-func (b *metricBatcher) stop() {
-	b.stopped = true
-	close(b.batchStop)
-	b.wg.Wait()
-
-	close(b.metricQueue)
-	b.processAfterShutdown()
+// stop stops the batcher, the queue is closed and the batcher sends the remaining metrics before returning
+// when the context is done first, the remaining sends are cancelled
+func (b *metricBatcher) stop(ctx context.Context) error {
+	return b.pipeline.stop(ctx, b.metricQueue.close, b.wg.Wait)
 }
 
 // runMetricBatcher runs the metric batcher
 func (b *metricBatcher) runMetricBatcher() {
 	defer b.wg.Done()
+	defer close(b.exited)
 
-	ticker := time.NewTicker(100 * time.Millisecond)
+	ticker := time.NewTicker(b.batch.Interval)
 	defer ticker.Stop()
 
-	var metrics []*metricMessage
+	var metrics []*MetricMessage
 	for {
 		select {
-		case <-b.batchStop:
-			if len(metrics) > 0 {
-				if err := b.sendMetricBatch(metrics); err != nil {
-					fmt.Printf("error sending final metric batch: %v\n", err)
-				}
+		case msg, ok := <-b.metricQueue.items:
+			if !ok {
+				b.reportError(b.sendMetricBatches(b.sendCtx, metrics))
+				return
 			}
-			return
-		case msg := <-b.metricQueue:
 			if msg == nil {
 				continue
 			}
 			metrics = append(metrics, msg)
-			if len(metrics) >= maxMetricBatchSize {
-				if err := b.sendMetricBatch(metrics); err != nil {
-					fmt.Printf("error sending metric batch: %v\n", err)
-				}
+			if len(metrics) >= b.batch.MaxSize {
+				b.reportError(b.sendMetricBatches(b.sendCtx, metrics))
 				metrics = nil
 			}
+		case req := <-b.flushes:
+			metrics = append(metrics, drainQueue(b.metricQueue)...)
+			req.done <- b.sendMetricBatches(req.ctx, metrics)
+			metrics = nil
 		case <-ticker.C:
 			if len(metrics) > 0 {
-				if err := b.sendMetricBatch(metrics); err != nil {
-					fmt.Printf("error sending metric batch: %v\n", err)
-				}
+				b.reportError(b.sendMetricBatches(b.sendCtx, metrics))
 				metrics = nil
 			}
 		}
 	}
 }
 
-// processAfterShutdown processes any remaining metrics in the queue after shutdown.
-func (b *metricBatcher) processAfterShutdown() {
-	var metrics []*metricMessage
-	for msg := range b.metricQueue {
-		if msg == nil {
-			continue
-		}
-		metrics = append(metrics, msg)
-		if len(metrics) >= 100 {
-			if err := b.sendMetricBatch(metrics); err != nil {
-				fmt.Printf("error sending shutdown metric batch: %v\n", err)
-			}
-			metrics = nil
-		}
-	}
-	if len(metrics) > 0 {
-		if err := b.sendMetricBatch(metrics); err != nil {
-			fmt.Printf("error sending final shutdown metric batch: %v\n", err)
+// sendMetricBatches exports the metrics in batches of at most the batch size
+func (b *metricBatcher) sendMetricBatches(ctx context.Context, metrics []*MetricMessage) error {
+	var errs []error
+	for len(metrics) > 0 {
+		n := min(len(metrics), b.batch.MaxSize)
+		if err := b.sendMetricBatch(ctx, metrics[:n]); err != nil {
+			errs = append(errs, &SendError{Pipeline: PipelineMetrics, Items: n, Err: err})
 		}
+		metrics = metrics[n:]
 	}
+	return errors.Join(errs...)
 }
 
-// sendMetricBatch sends a metric batch to the server
-func (b *metricBatcher) sendMetricBatch(metrics []*metricMessage) error {
+// sendMetricBatch exports a metric batch
+func (b *metricBatcher) sendMetricBatch(ctx context.Context, metrics []*MetricMessage) error {
 	if len(metrics) == 0 {
 		return nil
 	}
-
-	batch := &messageBatch{
-		Token:   b.token,
-		Metrics: metrics,
-	}
-
-	batchBytes, err := json.Marshal(batch)
-	if err != nil {
-		return err
-	}
-
-	err = b.sendBatch(batchBytes)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// sendBatch sends a batch to the server
-func (b *metricBatcher) sendBatch(batchBytes []byte) error {
-	req, err := http.NewRequest("POST", b.endpoint+metricEndpoint, bytes.NewBuffer(batchBytes))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+b.token)
-
-	resp, err := b.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	return nil
+	return b.sends.export(ctx, len(metrics), func(ctx context.Context) error {
+		return b.exporter.ExportMetrics(ctx, &MetricBatch{Metrics: metrics})
+	})
 }
@@ -3,7 +3,6 @@ package vigilant
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"sort"
 	"strings"
 	"sync"
@@ -18,31 +17,34 @@ type metricCollector struct {
 
 	interval time.Duration
 
+	// telemetry returns gauges added to the buckets at the end of every interval, it is nil when disabled
+	telemetry func() []*gaugeEvent
+
 	counterSeries   map[string]*counterSeries
 	gaugeSeries     map[string]*gaugeSeries
 	histogramSeries map[string]*histogramSeries
 
-	counterEvents   chan *counterEvent
-	gaugeEvents     chan *gaugeEvent
-	histogramEvents chan *histogramEvent
+	counterEvents   *queue[*counterEvent]
+	gaugeEvents     *queue[*gaugeEvent]
+	histogramEvents *queue[*histogramEvent]
 
 	mux      sync.RWMutex
+	flushes  chan flushRequest
+	exited   chan struct{}
 	stopChan chan struct{}
-	stopped  bool
 	wg       sync.WaitGroup
 }
 
 // newMetricCollector creates a new metricCollector
 func newMetricCollector(
 	interval time.Duration,
-	token string,
-	endpoint string,
-	httpClient *http.Client,
+	exporter Exporter,
+	queueConfig QueueConfig,
+	diag *diagnostics,
 ) *metricCollector {
 	metricSender := newMetricSender(
-		token,
-		endpoint,
-		httpClient,
+		exporter,
+		diag,
 	)
 	return &metricCollector{
 		sender:          metricSender,
@@ -50,12 +52,13 @@ func newMetricCollector(
 		counterSeries:   make(map[string]*counterSeries),
 		gaugeSeries:     make(map[string]*gaugeSeries),
 		histogramSeries: make(map[string]*histogramSeries),
-		counterEvents:   make(chan *counterEvent, 1000),
-		gaugeEvents:     make(chan *gaugeEvent, 1000),
-		histogramEvents: make(chan *histogramEvent, 1000),
+		counterEvents:   newQueue[*counterEvent](queueConfig),
+		gaugeEvents:     newQueue[*gaugeEvent](queueConfig),
+		histogramEvents: newQueue[*histogramEvent](queueConfig),
 		mux:             sync.RWMutex{},
+		flushes:         make(chan flushRequest),
+		exited:          make(chan struct{}),
 		stopChan:        make(chan struct{}),
-		stopped:         false,
 		wg:              sync.WaitGroup{},
 	}
 }
@@ -63,49 +66,63 @@ func newMetricCollector(
 // start starts the collector, the sender, and the event processor
 func (c *metricCollector) start() {
 	c.wg.Add(2)
-	go c.sender.start()
+	c.sender.start()
 	go c.processEvents()
 	go c.runTicker()
 }
 
-// stop stops the collector and the sender using simplified shutdown logic
-func (c *metricCollector) stop() {
-	c.stopped = true
-	close(c.stopChan)
-	c.wg.Wait()
+// stop stops the collector and the sender
+// the event queues are closed first so the event processor drains them before the final send,
+// and the context bounds the whole stop, the sends are cancelled when it is done first
+func (c *metricCollector) stop(ctx context.Context) error {
+	return c.sender.stop(ctx, func() {
+		c.counterEvents.close()
+		c.gaugeEvents.close()
+		c.histogramEvents.close()
 
-	close(c.counterEvents)
-	close(c.gaugeEvents)
-	close(c.histogramEvents)
+		close(c.stopChan)
+		c.wg.Wait()
 
-	c.processAfterShutdown()
-	c.sendAfterShutdown()
+		c.sendAfterShutdown()
+	})
+}
 
-	c.sender.stop()
+// flush processes the queued events and sends the metrics currently held in buckets without stopping
+func (c *metricCollector) flush(ctx context.Context) error {
+	if err := requestFlush(ctx, c.flushes, c.exited); err != nil {
+		return err
+	}
+	c.sendAfterShutdown()
+	return c.sender.flush(ctx)
 }
 
-// addCounter adds a counter event to the collector
+// addCounter adds a counter event to the collector, it is a noop once the collector is stopped
 func (c *metricCollector) addCounter(event *counterEvent) {
-	if c.stopped {
+	if event == nil {
 		return
 	}
-	c.counterEvents <- event
+	c.counterEvents.push(event)
 }
 
-// addGauge adds a gauge event to the collector
+// addGauge adds a gauge event to the collector, it is a noop once the collector is stopped
 func (c *metricCollector) addGauge(event *gaugeEvent) {
-	if c.stopped {
+	if event == nil {
 		return
 	}
-	c.gaugeEvents <- event
+	c.gaugeEvents.push(event)
 }
 
-// addHistogram adds a histogram event to the collector
+// addHistogram adds a histogram event to the collector, it is a noop once the collector is stopped
 func (c *metricCollector) addHistogram(event *histogramEvent) {
-	if c.stopped {
+	if event == nil {
 		return
 	}
-	c.histogramEvents <- event
+	c.histogramEvents.push(event)
+}
+
+// droppedCount returns the number of events dropped by the overflow policy of the event queues
+func (c *metricCollector) droppedCount() uint64 {
+	return c.counterEvents.droppedCount() + c.gaugeEvents.droppedCount() + c.histogramEvents.droppedCount()
 }
 
 // runTicker runs the ticker for the collector
@@ -173,37 +190,54 @@ func (c *metricCollector) runTicker() {
 	}
 }
 
-// processEvents reads metric events from the channel and updates the buckets.
+// processEvents reads metric events from the queues and updates the buckets until every queue is closed
 func (c *metricCollector) processEvents() {
 	defer c.wg.Done()
-	for {
+	defer close(c.exited)
+
+	counterEvents := c.counterEvents.items
+	gaugeEvents := c.gaugeEvents.items
+	histogramEvents := c.histogramEvents.items
+	for counterEvents != nil || gaugeEvents != nil || histogramEvents != nil {
 		select {
-		case <-c.stopChan:
-			return
-		case event, ok := <-c.counterEvents:
+		case event, ok := <-counterEvents:
 			if !ok {
+				counterEvents = nil
 				continue
 			}
 			if event == nil {
 				continue
 			}
 			c.processCounterEvent(event)
-		case event, ok := <-c.gaugeEvents:
+		case event, ok := <-gaugeEvents:
 			if !ok {
+				gaugeEvents = nil
 				continue
 			}
 			if event == nil {
 				continue
 			}
 			c.processGaugeEvent(event)
-		case event, ok := <-c.histogramEvents:
+		case event, ok := <-histogramEvents:
 			if !ok {
+				histogramEvents = nil
 				continue
 			}
 			if event == nil {
 				continue
 			}
 			c.processHistogramEvent(event)
+		case req := <-c.flushes:
+			for _, event := range drainQueue(c.counterEvents) {
+				c.processCounterEvent(event)
+			}
+			for _, event := range drainQueue(c.gaugeEvents) {
+				c.processGaugeEvent(event)
+			}
+			for _, event := range drainQueue(c.histogramEvents) {
+				c.processHistogramEvent(event)
+			}
+			req.done <- nil
 		}
 	}
 }
@@ -287,29 +321,14 @@ func (c *metricCollector) processHistogramEvent(event *histogramEvent) {
 	}
 }
 
-// processAfterShutdown drains event channels after goroutines have stopped.
-func (c *metricCollector) processAfterShutdown() {
-	processedCounters := 0
-	for event := range c.counterEvents {
-		c.processCounterEvent(event)
-		processedCounters++
-	}
-
-	processedGauges := 0
-	for event := range c.gaugeEvents {
-		c.processGaugeEvent(event)
-		processedGauges++
-	}
-
-	processedHistograms := 0
-	for event := range c.histogramEvents {
-		c.processHistogramEvent(event)
-		processedHistograms++
-	}
-}
-
 // sendMetricsForInterval sends the metrics for the interval
 func (c *metricCollector) sendMetricsForInterval(intervalStart time.Time) {
+	if c.telemetry != nil {
+		for _, event := range c.telemetry() {
+			c.processGaugeEvent(event)
+		}
+	}
+
 	c.mux.Lock()
 	metricsToSend := c.aggregateMetrics(intervalStart)
 	c.resetMetrics()
@@ -320,7 +339,7 @@ func (c *metricCollector) sendMetricsForInterval(intervalStart time.Time) {
 	}
 }
 
-// sendAfterShutdown sends all metrics currently held in buckets.
+// sendAfterShutdown sends all metrics currently held in buckets, it is also used by flush
 func (c *metricCollector) sendAfterShutdown() {
 	c.mux.Lock()
 	intervalStart := time.Now().Truncate(c.interval)
@@ -361,7 +380,7 @@ func (c *metricCollector) aggregateMetrics(
 	aggregatedMetrics := newAggregatedMetrics()
 
 	for _, counter := range c.counterSeries {
-		aggregatedMetrics.counterMetrics = append(aggregatedMetrics.counterMetrics, &counterMessage{
+		aggregatedMetrics.counterMetrics = append(aggregatedMetrics.counterMetrics, &CounterMessage{
 			Timestamp:  timestamp,
 			MetricName: counter.name,
 			Value:      counter.value,
@@ -370,7 +389,7 @@ func (c *metricCollector) aggregateMetrics(
 	}
 
 	for _, gauge := range c.gaugeSeries {
-		aggregatedMetrics.gaugeMetrics = append(aggregatedMetrics.gaugeMetrics, &gaugeMessage{
+		aggregatedMetrics.gaugeMetrics = append(aggregatedMetrics.gaugeMetrics, &GaugeMessage{
 			Timestamp:  timestamp,
 			MetricName: gauge.name,
 			Value:      gauge.value,
@@ -379,7 +398,7 @@ func (c *metricCollector) aggregateMetrics(
 	}
 
 	for _, histogram := range c.histogramSeries {
-		aggregatedMetrics.histogramMetrics = append(aggregatedMetrics.histogramMetrics, &histogramMessage{
+		aggregatedMetrics.histogramMetrics = append(aggregatedMetrics.histogramMetrics, &HistogramMessage{
 			Timestamp:  timestamp,
 			MetricName: histogram.name,
 			Values:     histogram.values,
@@ -1,45 +1,31 @@
 package vigilant
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"context"
+	"errors"
 	"sync"
 )
 
-const (
-	metricEndpoint = "/api/message"
-)
-
 // metricSender is a struct that contains the queues for the metrics
-// it immediately sends batches of metrics to the server
+// it immediately exports batches of metrics
 type metricSender struct {
-	token    string
-	endpoint string
+	aggsQueue *queue[*aggregatedMetrics]
 
-	aggsQueue chan *aggregatedMetrics
+	exporter Exporter
 
-	client *http.Client
-
-	stopped   bool
-	batchStop chan struct{}
-	wg        sync.WaitGroup
+	*pipeline
+	wg sync.WaitGroup
 }
 
 // newMetricSender creates a new metricSender
 func newMetricSender(
-	token string,
-	endpoint string,
-	httpClient *http.Client,
+	exporter Exporter,
+	diag *diagnostics,
 ) *metricSender {
 	return &metricSender{
-		token:     token,
-		endpoint:  endpoint,
-		stopped:   false,
-		aggsQueue: make(chan *aggregatedMetrics, 100),
-		batchStop: make(chan struct{}),
-		client:    httpClient,
+		aggsQueue: newQueue[*aggregatedMetrics](QueueConfig{Capacity: 100}),
+		exporter:  exporter,
+		pipeline:  newPipeline(diag),
 	}
 }
 
@@ -49,110 +35,70 @@ func (s *metricSender) start() {
 	go s.runMetricSender()
 }
 
-// sendAggregatedMetrics sends a batch to the sender's queue
+// sendAggregatedMetrics sends a batch to the sender's queue, it is a noop once the sender is stopped
 func (s *metricSender) sendAggregatedMetrics(metrics *aggregatedMetrics) {
-	if metrics == nil || s.stopped {
+	if metrics == nil {
 		return
 	}
-	s.aggsQueue <- metrics
+	s.aggsQueue.push(metrics)
 }
 
-// runMetricSender runs the metric sender
+// runMetricSender runs the metric sender until its queue is closed and drained
 func (s *metricSender) runMetricSender() {
 	defer s.wg.Done()
+	defer close(s.exited)
 
 	for {
 		select {
-		case <-s.batchStop:
-			return
-		case aggs := <-s.aggsQueue:
-			if aggs == nil {
-				continue
+		case aggs, ok := <-s.aggsQueue.items:
+			if !ok {
+				return
 			}
-			if len(aggs.counterMetrics) > 0 || len(aggs.gaugeMetrics) > 0 || len(aggs.histogramMetrics) > 0 {
-				s.sendMetrics(aggs)
+			s.reportError(s.sendMetrics(s.sendCtx, aggs))
+		case req := <-s.flushes:
+			var errs []error
+			for _, aggs := range drainQueue(s.aggsQueue) {
+				errs = append(errs, s.sendMetrics(req.ctx, aggs))
 			}
+			req.done <- errors.Join(errs...)
 		}
 	}
 }
 
-// stop stops the sender and processes remaining metrics
-func (s *metricSender) stop() {
-	s.stopped = true
-	close(s.batchStop)
-	s.wg.Wait()
-
-	close(s.aggsQueue)
-	s.processAfterShutdown()
+// stop stops the sender once drain has queued the last metrics, the queue is then closed and the sender sends the remaining metrics before returning
+// when the context is done first, the remaining sends are cancelled, including those drain waits on
+func (s *metricSender) stop(ctx context.Context, drain func()) error {
+	return s.pipeline.stop(ctx, func() {
+		drain()
+		s.aggsQueue.close()
+	}, s.wg.Wait)
 }
 
-// processAfterShutdown processes any remaining aggregated metrics in the queue after shutdown.
-func (s *metricSender) processAfterShutdown() {
-	for aggs := range s.aggsQueue {
-		if aggs == nil {
-			continue
-		}
-		if len(aggs.counterMetrics) > 0 || len(aggs.gaugeMetrics) > 0 || len(aggs.histogramMetrics) > 0 {
-			err := s.sendMetrics(aggs)
-			if err != nil {
-				fmt.Println("error sending metrics after shutdown", err)
-			}
-		}
-	}
-}
-
-// sendMetrics sends a counter batch to the server
+// sendMetrics exports a batch of aggregated metrics
 func (s *metricSender) sendMetrics(
+	ctx context.Context,
 	metrics *aggregatedMetrics,
 ) error {
-	counterCount := len(metrics.counterMetrics)
-	gaugeCount := len(metrics.gaugeMetrics)
-	histogramCount := len(metrics.histogramMetrics)
-
-	if counterCount == 0 && gaugeCount == 0 && histogramCount == 0 {
+	if metrics == nil {
 		return nil
 	}
 
-	batch := &messageBatch{
-		Token: s.token,
+	batch := &MetricBatch{
+		Counters:   metrics.counterMetrics,
+		Gauges:     metrics.gaugeMetrics,
+		Histograms: metrics.histogramMetrics,
 	}
-
-	batch.MetricsCounters = metrics.counterMetrics
-	batch.MetricsGauges = metrics.gaugeMetrics
-	batch.MetricsHistograms = metrics.histogramMetrics
-
-	batchBytes, err := json.Marshal(batch)
-	if err != nil {
-		fmt.Println("error marshalling metrics", err)
-		return err
-	}
-
-	err = s.sendBatch(batchBytes)
-	if err != nil {
-		fmt.Println("error sending batch", err)
-		return err
-	}
-
-	return nil
-}
-
-// sendBatch sends a batch to the server
-func (s *metricSender) sendBatch(batchBytes []byte) error {
-	req, err := http.NewRequest("POST", s.endpoint+metricEndpoint, bytes.NewBuffer(batchBytes))
-	if err != nil {
-		fmt.Println("error creating request", err)
-		return err
+	if batch.empty() {
+		return nil
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.token)
-
-	resp, err := s.client.Do(req)
+	count := len(batch.Counters) + len(batch.Gauges) + len(batch.Histograms)
+	err := s.sends.export(ctx, count, func(ctx context.Context) error {
+		return s.exporter.ExportMetrics(ctx, batch)
+	})
 	if err != nil {
-		fmt.Println("error sending batch", err)
-		return err
+		return &SendError{Pipeline: PipelineCollector, Items: count, Err: err}
 	}
-	defer resp.Body.Close()
 
 	return nil
 }
@@ -1,5 +1,7 @@
 package vigilant
 
+import "context"
+
 // Metric captures a metric
 //
 // Use this function when you want to capture a metric.
@@ -8,59 +10,59 @@ package vigilant
 //
 //	MetricEvent("my_metric", 1.0, vigilant.Tag("env", "prod"))
 func MetricEvent(name string, value float64, tags ...MetricTag) {
-	if gateNilGlobalInstance() {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	metric := createMetricMessage(name, value, tags...)
-	if metric == nil {
+	client.MetricEvent(name, value, tags...)
+}
+
+// MetricEventCtx captures a metric tagged with the attributes stored in the context
+//
+// Use this function when you want to capture a metric with request-scoped tags.
+//
+// Example:
+//
+//	MetricEventCtx(ctx, "my_metric", 1.0, vigilant.Tag("env", "prod"))
+func MetricEventCtx(ctx context.Context, name string, value float64, tags ...MetricTag) {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureMetric(metric)
+	client.MetricEventCtx(ctx, name, value, tags...)
 }
 
 // DEPRECATED: Use MetricEvent instead
 // MetricCounter captures a counter metric
 func MetricCounter(name string, value float64, tags ...MetricTag) {
-	if gateNilGlobalInstance() || value < 0 {
-		return
-	}
-
-	counter := createCounterEvent(name, value, tags...)
-	if counter == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureCounter(counter)
+	client.MetricCounter(name, value, tags...)
 }
 
 // DEPRECATED: Use MetricEvent instead
 // MetricGauge captures a gauge metric
 func MetricGauge(name string, value float64, mode GaugeMode, tags ...MetricTag) {
-	if gateNilGlobalInstance() || value < 0 {
-		return
-	}
-
-	gauge := createGaugeEvent(name, value, mode, tags...)
-	if gauge == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureGauge(gauge)
+	client.MetricGauge(name, value, mode, tags...)
 }
 
 // DEPRECATED: Use MetricEvent instead
 // MetricHistogram captures a histogram metric
 func MetricHistogram(name string, value float64, tags ...MetricTag) {
-	if gateNilGlobalInstance() || value < 0 {
-		return
-	}
-
-	histogram := createHistogramEvent(name, value, tags...)
-	if histogram == nil {
+	client := globalClient.Load()
+	if gateNilGlobalInstance(client) {
 		return
 	}
 
-	globalInstance.captureHistogram(histogram)
+	client.MetricHistogram(name, value, tags...)
 }
@@ -0,0 +1,386 @@
+package vigilant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOTLPEndpoint = "http://localhost:4318"
+	otlpLogsPath        = "/v1/logs"
+	otlpMetricsPath     = "/v1/metrics"
+	otlpScopeName       = "github.com/vigilant-run/vigilant-golang"
+
+	otlpTemporalityDelta = 1
+)
+
+// defaultOTLPHistogramBounds are the explicit bucket bounds used by the OpenTelemetry SDKs
+var defaultOTLPHistogramBounds = []float64{0, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 7500, 10000}
+
+// OTLPConfig is the configuration of the OTLP exporter
+type OTLPConfig struct {
+	// Endpoint is the base URL of the OTLP/HTTP receiver, the /v1/logs and /v1/metrics paths are added to it
+	Endpoint string
+
+	// Headers are added to every request, e.g. for authentication with the collector
+	Headers map[string]string
+
+	// HistogramBounds are the explicit bucket bounds of the exported histograms
+	HistogramBounds []float64
+}
+
+// withDefaults returns the OTLP config with the zero fields set to the defaults
+func (o OTLPConfig) withDefaults() OTLPConfig {
+	if o.Endpoint == "" {
+		o.Endpoint = defaultOTLPEndpoint
+	}
+	o.Endpoint = strings.TrimSuffix(o.Endpoint, "/")
+	if len(o.HistogramBounds) == 0 {
+		o.HistogramBounds = defaultOTLPHistogramBounds
+	} else {
+		o.HistogramBounds = slices.Sorted(slices.Values(o.HistogramBounds))
+	}
+	return o
+}
+
+// otlpExporter is the Exporter that sends batches to an OpenTelemetry collector as OTLP/HTTP JSON
+type otlpExporter struct {
+	resource  otlpResource
+	bounds    []float64
+	transport *httpTransport
+}
+
+// NewOTLPExporter creates an Exporter that sends batches to an OTLP/HTTP receiver as JSON
+// the service name of the resource is the config name, the retry and compression settings of the config are used
+func NewOTLPExporter(config *VigilantConfig, otlp OTLPConfig) Exporter {
+	otlp = otlp.withDefaults()
+	transport := newHTTPTransport(
+		"",
+		otlp.Endpoint,
+		&http.Client{},
+		config.Retry,
+		config.Compression,
+		otlp.Headers,
+		nil,
+		newDiagnostics(config),
+	)
+
+	return &otlpExporter{
+		resource: otlpResource{
+			Attributes: []otlpKeyValue{newOTLPKeyValue("service.name", config.Name)},
+		},
+		bounds:    otlp.HistogramBounds,
+		transport: transport,
+	}
+}
+
+// ExportLogs sends the logs as OTLP log records
+func (e *otlpExporter) ExportLogs(ctx context.Context, logs []*LogMessage) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	records := make([]otlpLogRecord, 0, len(logs))
+	for _, log := range logs {
+		severityNumber, severityText := otlpSeverity(log.Level)
+		records = append(records, otlpLogRecord{
+			TimeUnixNano:         otlpTime(log.Timestamp),
+			ObservedTimeUnixNano: otlpTime(log.Timestamp),
+			SeverityNumber:       severityNumber,
+			SeverityText:         severityText,
+			Body:                 otlpAnyValue{StringValue: &log.Body},
+			Attributes:           otlpAttributes(log.Attributes),
+		})
+	}
+
+	return e.send(ctx, otlpLogsPath, &otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: e.resource,
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: otlpScopeName},
+				LogRecords: records,
+			}},
+		}},
+	})
+}
+
+// ExportMetrics sends the metrics as OTLP metrics
+// metric events and counters become delta sums, gauges become gauges and histograms become explicit bucket histograms
+func (e *otlpExporter) ExportMetrics(ctx context.Context, batch *MetricBatch) error {
+	if batch == nil || batch.empty() {
+		return nil
+	}
+
+	now := otlpTime(time.Now())
+	metrics := make([]otlpMetric, 0, len(batch.Metrics)+len(batch.Counters)+len(batch.Gauges)+len(batch.Histograms))
+	for _, metric := range batch.Metrics {
+		metrics = append(metrics, otlpMetric{
+			Name: metric.Name,
+			Sum: &otlpSum{
+				AggregationTemporality: otlpTemporalityDelta,
+				DataPoints: []otlpNumberDataPoint{{
+					Attributes:        otlpAttributes(metric.Attributes),
+					StartTimeUnixNano: otlpTime(metric.Timestamp),
+					TimeUnixNano:      otlpTime(metric.Timestamp),
+					AsDouble:          metric.Value,
+				}},
+			},
+		})
+	}
+	for _, counter := range batch.Counters {
+		metrics = append(metrics, otlpMetric{
+			Name: counter.MetricName,
+			Sum: &otlpSum{
+				AggregationTemporality: otlpTemporalityDelta,
+				IsMonotonic:            true,
+				DataPoints: []otlpNumberDataPoint{{
+					Attributes:        otlpAttributes(counter.Tags),
+					StartTimeUnixNano: otlpTime(counter.Timestamp),
+					TimeUnixNano:      now,
+					AsDouble:          counter.Value,
+				}},
+			},
+		})
+	}
+	for _, gauge := range batch.Gauges {
+		metrics = append(metrics, otlpMetric{
+			Name: gauge.MetricName,
+			Gauge: &otlpGauge{
+				DataPoints: []otlpNumberDataPoint{{
+					Attributes:   otlpAttributes(gauge.Tags),
+					TimeUnixNano: now,
+					AsDouble:     gauge.Value,
+				}},
+			},
+		})
+	}
+	for _, histogram := range batch.Histograms {
+		if len(histogram.Values) == 0 {
+			continue
+		}
+		metrics = append(metrics, otlpMetric{
+			Name: histogram.MetricName,
+			Histogram: &otlpHistogram{
+				AggregationTemporality: otlpTemporalityDelta,
+				DataPoints:             []otlpHistogramDataPoint{e.histogramDataPoint(histogram, now)},
+			},
+		})
+	}
+
+	return e.send(ctx, otlpMetricsPath, &otlpMetricsRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: e.resource,
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: otlpScopeName},
+				Metrics: metrics,
+			}},
+		}},
+	})
+}
+
+// Shutdown does nothing, the exporter holds no resources
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// histogramDataPoint buckets the histogram values with the configured bounds
+func (e *otlpExporter) histogramDataPoint(histogram *HistogramMessage, now string) otlpHistogramDataPoint {
+	bucketCounts := make([]uint64, len(e.bounds)+1)
+	sum, minValue, maxValue := 0.0, histogram.Values[0], histogram.Values[0]
+	for _, value := range histogram.Values {
+		bucketCounts[sort.SearchFloat64s(e.bounds, value)]++
+		sum += value
+		minValue = min(minValue, value)
+		maxValue = max(maxValue, value)
+	}
+
+	counts := make([]string, len(bucketCounts))
+	for i, count := range bucketCounts {
+		counts[i] = strconv.FormatUint(count, 10)
+	}
+
+	return otlpHistogramDataPoint{
+		Attributes:        otlpAttributes(histogram.Tags),
+		StartTimeUnixNano: otlpTime(histogram.Timestamp),
+		TimeUnixNano:      now,
+		Count:             strconv.Itoa(len(histogram.Values)),
+		Sum:               sum,
+		Min:               minValue,
+		Max:               maxValue,
+		BucketCounts:      counts,
+		ExplicitBounds:    e.bounds,
+	}
+}
+
+// send marshals the request and sends it to the given path
+func (e *otlpExporter) send(ctx context.Context, path string, request any) error {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return e.transport.send(ctx, path, requestBytes)
+}
+
+// otlpSeverity returns the OTLP severity number and text of a log level
+func otlpSeverity(level LogLevel) (int, string) {
+	switch level {
+	case LEVEL_TRACE:
+		return 1, "TRACE"
+	case LEVEL_DEBUG:
+		return 5, "DEBUG"
+	case LEVEL_INFO:
+		return 9, "INFO"
+	case LEVEL_WARN:
+		return 13, "WARN"
+	case LEVEL_ERROR:
+		return 17, "ERROR"
+	default:
+		return 0, string(level)
+	}
+}
+
+// otlpTime formats a time as OTLP JSON nanoseconds, which are encoded as a string
+func otlpTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// otlpAttributes converts a map of attributes into OTLP key values, sorted by key
+func otlpAttributes(attrs map[string]string) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	keyValues := make([]otlpKeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		keyValues = append(keyValues, newOTLPKeyValue(key, value))
+	}
+	slices.SortFunc(keyValues, func(a, b otlpKeyValue) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return keyValues
+}
+
+// newOTLPKeyValue creates an OTLP key value with a string value
+func newOTLPKeyValue(key string, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: &value}}
+}
+
+// otlpLogsRequest is the body of an OTLP/HTTP logs export request
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// otlpResourceLogs is the logs of a resource
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+// otlpScopeLogs is the logs of an instrumentation scope
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+// otlpLogRecord is an OTLP log record
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+// otlpMetricsRequest is the body of an OTLP/HTTP metrics export request
+type otlpMetricsRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+// otlpResourceMetrics is the metrics of a resource
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+// otlpScopeMetrics is the metrics of an instrumentation scope
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+// otlpMetric is an OTLP metric, exactly one of Sum, Gauge and Histogram is set
+type otlpMetric struct {
+	Name      string         `json:"name"`
+	Sum       *otlpSum       `json:"sum,omitempty"`
+	Gauge     *otlpGauge     `json:"gauge,omitempty"`
+	Histogram *otlpHistogram `json:"histogram,omitempty"`
+}
+
+// otlpSum is an OTLP sum metric
+type otlpSum struct {
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+// otlpGauge is an OTLP gauge metric
+type otlpGauge struct {
+	DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+// otlpHistogram is an OTLP explicit bucket histogram metric
+type otlpHistogram struct {
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+// otlpNumberDataPoint is a data point of a sum or gauge
+type otlpNumberDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          float64        `json:"asDouble"`
+}
+
+// otlpHistogramDataPoint is a data point of a histogram
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	Count             string         `json:"count"`
+	Sum               float64        `json:"sum"`
+	Min               float64        `json:"min"`
+	Max               float64        `json:"max"`
+	BucketCounts      []string       `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+// otlpResource is an OTLP resource
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+// otlpScope is an OTLP instrumentation scope
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+// otlpKeyValue is an OTLP attribute
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// otlpAnyValue is an OTLP attribute value, only string values are used
+type otlpAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+}
@@ -0,0 +1,268 @@
+package vigilant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// otlpRequest is a request received by an OTLP receiver, the body is decoded without the exporter types
+// so the test checks the field names and JSON types on the wire
+type otlpRequest struct {
+	path   string
+	header http.Header
+	body   map[string]any
+}
+
+// newOTLPReceiver starts a receiver that records the requests and returns the exporter sending to it
+func newOTLPReceiver(t *testing.T, otlp OTLPConfig) (Exporter, func() []otlpRequest) {
+	t.Helper()
+	var mux sync.Mutex
+	var requests []otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mux.Lock()
+		requests = append(requests, otlpRequest{path: r.URL.Path, header: r.Header.Clone(), body: body})
+		mux.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	otlp.Endpoint = server.URL
+	exporter := NewOTLPExporter(NewConfigBuilder().WithName("checkout").WithInternalLogger(discardLogger{}).Build(), otlp)
+	return exporter, func() []otlpRequest {
+		mux.Lock()
+		defer mux.Unlock()
+		return requests
+	}
+}
+
+// otlpField walks the decoded JSON with map keys and slice indexes, failing the test when a step is missing
+func otlpField(t *testing.T, value any, steps ...any) any {
+	t.Helper()
+	for _, step := range steps {
+		switch step := step.(type) {
+		case string:
+			object, ok := value.(map[string]any)
+			if !ok {
+				t.Fatalf("expected an object for %q, got %v", step, value)
+			}
+			if value, ok = object[step]; !ok {
+				t.Fatalf("missing field %q in %v", step, object)
+			}
+		case int:
+			array, ok := value.([]any)
+			if !ok || step >= len(array) {
+				t.Fatalf("expected an array with index %d, got %v", step, value)
+			}
+			value = array[step]
+		}
+	}
+	return value
+}
+
+// checkOTLPResource checks the service.name attribute of the resource of the first resource entry
+func checkOTLPResource(t *testing.T, body map[string]any, resourceField string) {
+	t.Helper()
+	attribute := otlpField(t, body, resourceField, 0, "resource", "attributes", 0)
+	if key := otlpField(t, attribute, "key"); key != "service.name" {
+		t.Errorf("expected the service.name resource attribute, got %v", key)
+	}
+	if value := otlpField(t, attribute, "value", "stringValue"); value != "checkout" {
+		t.Errorf("expected the service name to be checkout, got %v", value)
+	}
+}
+
+func TestOTLPExporterLogs(t *testing.T) {
+	exporter, requests := newOTLPReceiver(t, OTLPConfig{})
+	timestamp := time.Unix(1700000000, 123456789)
+
+	levels := []LogLevel{LEVEL_TRACE, LEVEL_DEBUG, LEVEL_INFO, LEVEL_WARN, LEVEL_ERROR}
+	var logs []*LogMessage
+	for _, level := range levels {
+		logs = append(logs, &LogMessage{Timestamp: timestamp, Level: level, Body: "body " + string(level), Attributes: map[string]string{"b": "2", "a": "1"}})
+	}
+	if err := exporter.ExportLogs(context.Background(), logs); err != nil {
+		t.Fatal(err)
+	}
+
+	received := requests()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(received))
+	}
+	request := received[0]
+	if request.path != "/v1/logs" {
+		t.Errorf("expected the /v1/logs path, got %s", request.path)
+	}
+	if contentType := request.header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected a JSON body, got %s", contentType)
+	}
+	if authorization := request.header.Get("Authorization"); authorization != "" {
+		t.Errorf("expected no Authorization header without a token, got %q", authorization)
+	}
+	checkOTLPResource(t, request.body, "resourceLogs")
+
+	scopeLogs := otlpField(t, request.body, "resourceLogs", 0, "scopeLogs", 0)
+	if name := otlpField(t, scopeLogs, "scope", "name"); name != otlpScopeName {
+		t.Errorf("expected the scope name %s, got %v", otlpScopeName, name)
+	}
+	severities := []struct {
+		number float64
+		text   string
+	}{{1, "TRACE"}, {5, "DEBUG"}, {9, "INFO"}, {13, "WARN"}, {17, "ERROR"}}
+	for i, severity := range severities {
+		record := otlpField(t, scopeLogs, "logRecords", i)
+		if number := otlpField(t, record, "severityNumber"); number != severity.number {
+			t.Errorf("record %d: expected severity number %v, got %v", i, severity.number, number)
+		}
+		if text := otlpField(t, record, "severityText"); text != severity.text {
+			t.Errorf("record %d: expected severity text %s, got %v", i, severity.text, text)
+		}
+		want := strconv.FormatInt(timestamp.UnixNano(), 10)
+		if nanos := otlpField(t, record, "timeUnixNano"); nanos != want {
+			t.Errorf("record %d: expected timeUnixNano to be the string %s, got %#v", i, want, nanos)
+		}
+		if nanos := otlpField(t, record, "observedTimeUnixNano"); nanos != want {
+			t.Errorf("record %d: expected observedTimeUnixNano to be the string %s, got %#v", i, want, nanos)
+		}
+		if body := otlpField(t, record, "body", "stringValue"); body != "body "+string(levels[i]) {
+			t.Errorf("record %d: unexpected body %v", i, body)
+		}
+		if key := otlpField(t, record, "attributes", 0, "key"); key != "a" {
+			t.Errorf("record %d: expected the attributes sorted by key, got %v first", i, key)
+		}
+		if value := otlpField(t, record, "attributes", 1, "value", "stringValue"); value != "2" {
+			t.Errorf("record %d: expected b=2, got %v", i, value)
+		}
+	}
+}
+
+func TestOTLPExporterMetrics(t *testing.T) {
+	exporter, requests := newOTLPReceiver(t, OTLPConfig{
+		Headers:         map[string]string{"X-Collector-Key": "secret"},
+		HistogramBounds: []float64{100, 5, 10},
+	})
+	timestamp := time.Unix(1700000000, 0)
+	tags := map[string]string{"route": "/pay"}
+
+	err := exporter.ExportMetrics(context.Background(), &MetricBatch{
+		Metrics:    []*MetricMessage{{Timestamp: timestamp, Name: "requests", Value: 3, Attributes: tags}},
+		Counters:   []*CounterMessage{{Timestamp: timestamp, MetricName: "orders", Value: 2, Tags: tags}},
+		Gauges:     []*GaugeMessage{{Timestamp: timestamp, MetricName: "inflight", Value: 7, Tags: tags}},
+		Histograms: []*HistogramMessage{{Timestamp: timestamp, MetricName: "latency", Values: []float64{1, 5, 7, 7, 300, 20000}, Tags: tags}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := requests()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(received))
+	}
+	request := received[0]
+	if request.path != "/v1/metrics" {
+		t.Errorf("expected the /v1/metrics path, got %s", request.path)
+	}
+	if key := request.header.Get("X-Collector-Key"); key != "secret" {
+		t.Errorf("expected the configured header, got %q", key)
+	}
+	if authorization := request.header.Get("Authorization"); authorization != "" {
+		t.Errorf("expected no Authorization header without a token, got %q", authorization)
+	}
+	checkOTLPResource(t, request.body, "resourceMetrics")
+
+	metrics := otlpField(t, request.body, "resourceMetrics", 0, "scopeMetrics", 0, "metrics")
+	start := strconv.FormatInt(timestamp.UnixNano(), 10)
+
+	event := otlpField(t, metrics, 0)
+	if name := otlpField(t, event, "name"); name != "requests" {
+		t.Errorf("expected the metric event first, got %v", name)
+	}
+	if temporality := otlpField(t, event, "sum", "aggregationTemporality"); temporality != float64(otlpTemporalityDelta) {
+		t.Errorf("expected a delta sum for the metric event, got %v", temporality)
+	}
+	if monotonic := otlpField(t, event, "sum", "isMonotonic"); monotonic != false {
+		t.Errorf("expected the metric event to be a non-monotonic sum, got %v", monotonic)
+	}
+	if value := otlpField(t, event, "sum", "dataPoints", 0, "asDouble"); value != 3.0 {
+		t.Errorf("expected the metric event value 3, got %v", value)
+	}
+	if value := otlpField(t, event, "sum", "dataPoints", 0, "startTimeUnixNano"); value != start {
+		t.Errorf("expected startTimeUnixNano to be the string %s, got %#v", start, value)
+	}
+	if value := otlpField(t, event, "sum", "dataPoints", 0, "attributes", 0, "value", "stringValue"); value != "/pay" {
+		t.Errorf("expected the route attribute, got %v", value)
+	}
+
+	counter := otlpField(t, metrics, 1)
+	if temporality := otlpField(t, counter, "sum", "aggregationTemporality"); temporality != float64(otlpTemporalityDelta) {
+		t.Errorf("expected a delta sum for the counter, got %v", temporality)
+	}
+	if monotonic := otlpField(t, counter, "sum", "isMonotonic"); monotonic != true {
+		t.Errorf("expected the counter to be a monotonic sum, got %v", monotonic)
+	}
+	if value := otlpField(t, counter, "sum", "dataPoints", 0, "asDouble"); value != 2.0 {
+		t.Errorf("expected the counter value 2, got %v", value)
+	}
+	if _, ok := otlpField(t, counter, "sum", "dataPoints", 0, "timeUnixNano").(string); !ok {
+		t.Error("expected timeUnixNano to be a string")
+	}
+
+	gauge := otlpField(t, metrics, 2)
+	if value := otlpField(t, gauge, "gauge", "dataPoints", 0, "asDouble"); value != 7.0 {
+		t.Errorf("expected the gauge value 7, got %v", value)
+	}
+	if _, ok := gauge.(map[string]any)["sum"]; ok {
+		t.Error("expected the gauge to have no sum")
+	}
+
+	histogram := otlpField(t, metrics, 3)
+	if temporality := otlpField(t, histogram, "histogram", "aggregationTemporality"); temporality != float64(otlpTemporalityDelta) {
+		t.Errorf("expected a delta histogram, got %v", temporality)
+	}
+	point := otlpField(t, histogram, "histogram", "dataPoints", 0)
+	if count := otlpField(t, point, "count"); count != "6" {
+		t.Errorf("expected the count to be the string 6, got %#v", count)
+	}
+	for field, want := range map[string]float64{"sum": 20320, "min": 1, "max": 20000} {
+		if value := otlpField(t, point, field); value != want {
+			t.Errorf("expected %s %v, got %v", field, want, value)
+		}
+	}
+	bounds := otlpField(t, point, "explicitBounds").([]any)
+	if len(bounds) != 3 || bounds[0] != 5.0 || bounds[1] != 10.0 || bounds[2] != 100.0 {
+		t.Errorf("expected the sorted bounds [5 10 100], got %v", bounds)
+	}
+	// the buckets are upper-inclusive, so 5 falls in the first bucket
+	counts := otlpField(t, point, "bucketCounts").([]any)
+	wantCounts := []any{"2", "2", "0", "2"}
+	if len(counts) != len(wantCounts) {
+		t.Fatalf("expected the bucket counts %v, got %v", wantCounts, counts)
+	}
+	for i := range wantCounts {
+		if counts[i] != wantCounts[i] {
+			t.Fatalf("expected the bucket counts %v as strings, got %#v", wantCounts, counts)
+		}
+	}
+}
+
+func TestOTLPExporterSkipsEmptyBatches(t *testing.T) {
+	exporter, requests := newOTLPReceiver(t, OTLPConfig{})
+	if err := exporter.ExportLogs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.ExportMetrics(context.Background(), &MetricBatch{}); err != nil {
+		t.Fatal(err)
+	}
+	if received := requests(); len(received) != 0 {
+		t.Fatalf("expected no requests, got %d", len(received))
+	}
+}
@@ -0,0 +1,182 @@
+package vigilant
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	maxBatchSize         = 100
+	defaultBatchInterval = 100 * time.Millisecond
+)
+
+// flushRequest asks a pipeline goroutine to send everything it holds
+// the goroutine replies on done once the sends have finished
+type flushRequest struct {
+	ctx  context.Context
+	done chan error
+}
+
+// requestFlush hands a flush request to a pipeline goroutine and waits for the reply
+// it returns nil if the goroutine has already exited, since stopping sends what it held
+func requestFlush(ctx context.Context, flushes chan<- flushRequest, exited <-chan struct{}) error {
+	req := flushRequest{ctx: ctx, done: make(chan error, 1)}
+	select {
+	case flushes <- req:
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-req.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pipeline is the shared state of a goroutine that sends batches to the server
+// it handles flush requests and collects the send errors that happen while stopping
+type pipeline struct {
+	sendCtx     context.Context
+	cancelSends context.CancelFunc
+
+	flushes chan flushRequest
+	exited  chan struct{}
+
+	stopping atomic.Bool
+	stopErrs []error
+
+	diag *diagnostics
+
+	sends sendStats
+}
+
+// newPipeline creates a new pipeline, its send errors are reported to diag
+func newPipeline(diag *diagnostics) *pipeline {
+	sendCtx, cancelSends := context.WithCancel(context.Background())
+	return &pipeline{
+		sendCtx:     sendCtx,
+		cancelSends: cancelSends,
+		flushes:     make(chan flushRequest),
+		exited:      make(chan struct{}),
+		diag:        diag,
+	}
+}
+
+// flush asks the pipeline goroutine to send everything it holds
+func (p *pipeline) flush(ctx context.Context) error {
+	return requestFlush(ctx, p.flushes, p.exited)
+}
+
+// stop closes the queue and waits for the pipeline goroutine to send what is left
+// when the context is done first, the in-flight sends are cancelled and the context error is returned with the send errors
+func (p *pipeline) stop(ctx context.Context, closeQueue func(), wait func()) error {
+	p.stopping.Store(true)
+	defer p.cancelSends()
+
+	done := make(chan struct{})
+	go func() {
+		closeQueue()
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return errors.Join(p.stopErrs...)
+	case <-ctx.Done():
+		p.cancelSends()
+		<-done
+		return errors.Join(append(p.stopErrs, ctx.Err())...)
+	}
+}
+
+// reportError hands a send error to the diagnostics, and keeps it for stop once the pipeline is stopping
+// joined errors are reported one by one, so the error handler receives each *SendError
+// it must only be called from the pipeline goroutine
+func (p *pipeline) reportError(err error) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			p.reportError(err)
+		}
+		return
+	}
+	p.diag.reportError(err)
+	if p.stopping.Load() {
+		p.stopErrs = append(p.stopErrs, err)
+	}
+}
+
+// sendStats counts the batches and items a pipeline exported, their retries and latency, and keeps the last failure
+// the counters are only written by the pipeline goroutine and can be read at any time
+type sendStats struct {
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+	sentItems   atomic.Uint64
+	failedItems atomic.Uint64
+	retries     atomic.Uint64
+	maxBatch    atomic.Int64
+	latency     atomic.Int64
+	lastLatency atomic.Int64
+	maxLatency  atomic.Int64
+	lastFail    atomic.Pointer[sendFailure]
+}
+
+// sendFailure is a failed export and when it happened
+type sendFailure struct {
+	err error
+	at  time.Time
+}
+
+// export calls the export function for a batch of the given number of items and records its outcome
+// the retries of the transport are counted through the context passed to the export function
+func (s *sendStats) export(ctx context.Context, items int, export func(ctx context.Context) error) error {
+	start := time.Now()
+	err := export(withRetryCounter(ctx, &s.retries))
+	s.record(items, time.Since(start), err)
+	return err
+}
+
+// record counts the outcome of an export
+func (s *sendStats) record(items int, latency time.Duration, err error) {
+	s.latency.Add(int64(latency))
+	s.lastLatency.Store(int64(latency))
+	if int64(latency) > s.maxLatency.Load() {
+		s.maxLatency.Store(int64(latency))
+	}
+	if int64(items) > s.maxBatch.Load() {
+		s.maxBatch.Store(int64(items))
+	}
+
+	if err != nil {
+		s.failed.Add(1)
+		s.failedItems.Add(uint64(items))
+		s.lastFail.Store(&sendFailure{err: err, at: time.Now()})
+		return
+	}
+	s.sent.Add(1)
+	s.sentItems.Add(uint64(items))
+}
+
+// retryCounterKey is the context key for the counter of the retries made by the transport
+type retryCounterKey struct{}
+
+// withRetryCounter returns a copy of the context carrying the retry counter of a pipeline
+func withRetryCounter(ctx context.Context, retries *atomic.Uint64) context.Context {
+	return context.WithValue(ctx, retryCounterKey{}, retries)
+}
+
+// countRetry increments the retry counter carried by the context, if any
+func countRetry(ctx context.Context) {
+	if retries, ok := ctx.Value(retryCounterKey{}).(*atomic.Uint64); ok {
+		retries.Add(1)
+	}
+}
@@ -0,0 +1,194 @@
+package vigilant
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultQueueCapacity   = 1000
+	defaultOverflowTimeout = 100 * time.Millisecond
+)
+
+// OverflowPolicy is what a pipeline does when its queue is full
+type OverflowPolicy string
+
+const (
+	// OverflowBlock blocks the caller until there is room in the queue
+	OverflowBlock OverflowPolicy = "block"
+
+	// OverflowDropNewest drops the item being added
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+
+	// OverflowDropOldest drops the oldest item in the queue to make room for the new one
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+
+	// OverflowBlockTimeout blocks the caller until there is room or the timeout passes, then drops the item
+	OverflowBlockTimeout OverflowPolicy = "block_timeout"
+)
+
+// QueueConfig is the configuration of a pipeline queue
+// zero fields use the defaults, which block when the queue is full like earlier versions
+type QueueConfig struct {
+	// Capacity is the number of items the queue holds
+	Capacity int
+
+	// Policy is what happens when the queue is full
+	Policy OverflowPolicy
+
+	// Timeout is how long OverflowBlockTimeout waits before dropping the item
+	Timeout time.Duration
+}
+
+// withDefaults returns the queue config with the zero fields set to the defaults
+func (q QueueConfig) withDefaults() QueueConfig {
+	if q.Capacity <= 0 {
+		q.Capacity = defaultQueueCapacity
+	}
+	if q.Policy == "" {
+		q.Policy = OverflowBlock
+	}
+	if q.Timeout <= 0 {
+		q.Timeout = defaultOverflowTimeout
+	}
+	return q
+}
+
+// DroppedCounts is the number of items each pipeline dropped because its queue was full
+type DroppedCounts struct {
+	// Logs is the number of logs dropped by the log pipeline
+	Logs uint64
+
+	// Metrics is the number of metrics dropped by the metric pipeline
+	Metrics uint64
+
+	// CollectorEvents is the number of counter, gauge and histogram events dropped by the collector
+	CollectorEvents uint64
+}
+
+// queue is a bounded channel that applies an overflow policy when it is full
+// the consumer reads from items directly until it is closed,
+// producers hold the read lock while sending so close never races with a send
+type queue[T any] struct {
+	items    chan T
+	policy   OverflowPolicy
+	timeout  time.Duration
+	enqueued atomic.Uint64
+	dropped  atomic.Uint64
+
+	mux    sync.RWMutex
+	closed bool
+}
+
+// newQueue creates a new queue from the given config
+func newQueue[T any](config QueueConfig) *queue[T] {
+	config = config.withDefaults()
+	return &queue[T]{
+		items:   make(chan T, config.Capacity),
+		policy:  config.Policy,
+		timeout: config.Timeout,
+	}
+}
+
+// push adds an item to the queue, applying the overflow policy if the queue is full
+// it reports whether the item was added, items pushed after close are discarded
+func (q *queue[T]) push(item T) bool {
+	added := q.add(item)
+	if added {
+		q.enqueued.Add(1)
+	}
+	return added
+}
+
+// add adds an item to the queue like push, without counting it
+func (q *queue[T]) add(item T) bool {
+	q.mux.RLock()
+	defer q.mux.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	switch q.policy {
+	case OverflowDropNewest:
+		select {
+		case q.items <- item:
+			return true
+		default:
+			q.dropped.Add(1)
+			return false
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.items <- item:
+				return true
+			default:
+			}
+			select {
+			case <-q.items:
+				q.dropped.Add(1)
+			default:
+			}
+		}
+	case OverflowBlockTimeout:
+		select {
+		case q.items <- item:
+			return true
+		default:
+		}
+		timer := time.NewTimer(q.timeout)
+		defer timer.Stop()
+		select {
+		case q.items <- item:
+			return true
+		case <-timer.C:
+			q.dropped.Add(1)
+			return false
+		}
+	default:
+		q.items <- item
+		return true
+	}
+}
+
+// close closes the queue once the in-flight pushes have finished
+// the consumer must keep reading until then, a blocked push would otherwise never return
+func (q *queue[T]) close() {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.items)
+}
+
+// depth returns the number of items waiting in the queue
+func (q *queue[T]) depth() int {
+	return len(q.items)
+}
+
+// enqueuedCount returns the number of items added to the queue
+func (q *queue[T]) enqueuedCount() uint64 {
+	return q.enqueued.Load()
+}
+
+// droppedCount returns the number of items dropped by the overflow policy
+func (q *queue[T]) droppedCount() uint64 {
+	return q.dropped.Load()
+}
+
+// drainQueue returns the items currently in the queue without waiting for more
+// it must only be called from the consumer goroutine
+func drainQueue[T any](q *queue[T]) []T {
+	var items []T
+	for range len(q.items) {
+		item, ok := <-q.items
+		if !ok {
+			break
+		}
+		items = append(items, item)
+	}
+	return items
+}
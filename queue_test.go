@@ -0,0 +1,128 @@
+package vigilant
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestQueueDropNewest(t *testing.T) {
+	q := newQueue[int](QueueConfig{Capacity: 2, Policy: OverflowDropNewest})
+	for i := range 4 {
+		if added, want := q.push(i), i < 2; added != want {
+			t.Fatalf("push %d: expected added=%v, got %v", i, want, added)
+		}
+	}
+	q.close()
+	if items := drainQueue(q); !slices.Equal(items, []int{0, 1}) {
+		t.Fatalf("expected the oldest items to be kept, got %v", items)
+	}
+	if q.enqueuedCount() != 2 || q.droppedCount() != 2 {
+		t.Fatalf("expected 2 enqueued and 2 dropped, got %d and %d", q.enqueuedCount(), q.droppedCount())
+	}
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	q := newQueue[int](QueueConfig{Capacity: 2, Policy: OverflowDropOldest})
+	for i := range 5 {
+		if !q.push(i) {
+			t.Fatalf("push %d: expected the item to be added", i)
+		}
+	}
+	q.close()
+	if items := drainQueue(q); !slices.Equal(items, []int{3, 4}) {
+		t.Fatalf("expected the newest items to be kept, got %v", items)
+	}
+	if q.enqueuedCount() != 5 || q.droppedCount() != 3 {
+		t.Fatalf("expected 5 enqueued and 3 dropped, got %d and %d", q.enqueuedCount(), q.droppedCount())
+	}
+}
+
+func TestQueueBlockTimeout(t *testing.T) {
+	q := newQueue[int](QueueConfig{Capacity: 1, Policy: OverflowBlockTimeout, Timeout: 20 * time.Millisecond})
+	q.push(0)
+
+	start := time.Now()
+	if q.push(1) {
+		t.Fatal("expected the item to be dropped after the timeout")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("expected the push to wait for the timeout, returned after %v", elapsed)
+	}
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		<-q.items
+	}()
+	if !q.push(2) {
+		t.Fatal("expected the item to be added once there is room")
+	}
+	if q.droppedCount() != 1 {
+		t.Fatalf("expected 1 dropped, got %d", q.droppedCount())
+	}
+}
+
+func TestQueueBlock(t *testing.T) {
+	q := newQueue[int](QueueConfig{Capacity: 1})
+	q.push(0)
+
+	pushed := make(chan bool)
+	go func() {
+		pushed <- q.push(1)
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("expected the push to block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	<-q.items
+	if !<-pushed {
+		t.Fatal("expected the item to be added once there is room")
+	}
+	if q.droppedCount() != 0 {
+		t.Fatalf("expected nothing dropped, got %d", q.droppedCount())
+	}
+}
+
+func TestQueueClosedDiscardsPushes(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowBlockTimeout} {
+		q := newQueue[int](QueueConfig{Capacity: 1, Policy: policy})
+		q.close()
+		q.close()
+		if q.push(1) {
+			t.Errorf("%s: expected a push after close to be discarded", policy)
+		}
+		if q.enqueuedCount() != 0 || q.droppedCount() != 0 {
+			t.Errorf("%s: expected a discarded push to be neither enqueued nor dropped", policy)
+		}
+	}
+}
+
+func TestDroppedCountsPerPipeline(t *testing.T) {
+	client := NewClient(NewConfigBuilder().
+		WithToken("tk_test").
+		WithInternalLogger(discardLogger{}).
+		WithExporters(&blockingExporter{blockLogs: true, blockMetrics: true}).
+		WithBatch(BatchConfig{MaxSize: 1}).
+		WithLogQueue(QueueConfig{Capacity: 1, Policy: OverflowDropNewest}).
+		WithMetricQueue(QueueConfig{Capacity: 1, Policy: OverflowDropNewest}).
+		Build())
+	defer func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		client.ShutdownContext(ctx)
+	}()
+
+	// the batchers are busy with the first item, the queue holds the second and the third is dropped
+	waitFor(t, 5*time.Second, func() bool {
+		client.LogInfo("log")
+		client.MetricEvent("metric", 1)
+		dropped := client.Dropped()
+		return dropped.Logs > 0 && dropped.Metrics > 0
+	})
+	if dropped := client.Dropped(); dropped.CollectorEvents != 0 {
+		t.Fatalf("expected the collector to drop nothing, got %d", dropped.CollectorEvents)
+	}
+}
@@ -0,0 +1,122 @@
+package vigilant
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	maxRecordLineSize = 16 * 1024 * 1024
+)
+
+// Record is a line of an NDJSON archive written by the file exporter
+// exactly one of the fields is set, depending on the type of the line
+type Record struct {
+	Log       *LogMessage
+	Metric    *MetricMessage
+	Counter   *CounterMessage
+	Gauge     *GaugeMessage
+	Histogram *HistogramMessage
+}
+
+// RecordError is returned by RecordReader for a malformed line
+type RecordError struct {
+	// Line is the line number of the malformed line, starting at 1
+	Line int
+
+	// Err is the reason the line is malformed
+	Err error
+}
+
+// Error returns the string representation of the error
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the reason the line is malformed
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// RecordReader reads the records of an NDJSON archive written by the file exporter
+type RecordReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewRecordReader creates a RecordReader reading from the given reader
+func NewRecordReader(r io.Reader) *RecordReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordLineSize)
+	return &RecordReader{scanner: scanner}
+}
+
+// Line returns the line number of the last line read, starting at 1
+func (r *RecordReader) Line() int {
+	return r.line
+}
+
+// Next reads the next record, it returns io.EOF once every line is read
+// a malformed line returns a *RecordError and the next call continues with the following line,
+// other errors come from the underlying reader and end the reading
+func (r *RecordReader) Next() (*Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record, err := parseRecord(line)
+		if err != nil {
+			return nil, &RecordError{Line: r.line, Err: err}
+		}
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// parseRecord parses a line into the message matching its type
+func parseRecord(line []byte) (*Record, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, err
+	}
+
+	record := &Record{}
+	var target any
+	switch header.Type {
+	case fileRecordLog:
+		record.Log = &LogMessage{}
+		target = record.Log
+	case fileRecordMetric:
+		record.Metric = &MetricMessage{}
+		target = record.Metric
+	case fileRecordCounter:
+		record.Counter = &CounterMessage{}
+		target = record.Counter
+	case fileRecordGauge:
+		record.Gauge = &GaugeMessage{}
+		target = record.Gauge
+	case fileRecordHistogram:
+		record.Histogram = &HistogramMessage{}
+		target = record.Histogram
+	case "":
+		return nil, fmt.Errorf("missing record type")
+	default:
+		return nil, fmt.Errorf("unknown record type %q", header.Type)
+	}
+
+	if err := json.Unmarshal(line, target); err != nil {
+		return nil, fmt.Errorf("invalid %s record: %w", header.Type, err)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,102 @@
+package vigilant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingExporter is an Exporter whose exports block until their context is done
+type blockingExporter struct {
+	blockLogs    bool
+	blockMetrics bool
+}
+
+// ExportLogs blocks until the context is done when blockLogs is set
+func (e *blockingExporter) ExportLogs(ctx context.Context, logs []*LogMessage) error {
+	if !e.blockLogs {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// ExportMetrics blocks until the context is done when blockMetrics is set
+func (e *blockingExporter) ExportMetrics(ctx context.Context, batch *MetricBatch) error {
+	if !e.blockMetrics {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Shutdown does nothing
+func (e *blockingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// deadlineErrors counts the context.DeadlineExceeded errors joined in err, leaving out those wrapped in a SendError
+func deadlineErrors(err error) int {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		count := 0
+		for _, err := range joined.Unwrap() {
+			count += deadlineErrors(err)
+		}
+		return count
+	}
+	if err == context.DeadlineExceeded {
+		return 1
+	}
+	return 0
+}
+
+func TestShutdownStopsPipelinesConcurrently(t *testing.T) {
+	for range 20 {
+		client := NewClient(NewConfigBuilder().
+			WithInternalLogger(discardLogger{}).
+			WithErrorHandler(func(error) {}).
+			WithExporters(&blockingExporter{blockLogs: true}).
+			Build())
+		client.LogInfo("stuck")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		err := client.ShutdownContext(ctx)
+		cancel()
+
+		var sendErr *SendError
+		if !errors.As(err, &sendErr) || sendErr.Pipeline != PipelineLogs {
+			t.Fatalf("expected the log send to fail, got %v", err)
+		}
+		if count := deadlineErrors(err); count != 1 {
+			t.Fatalf("expected only the log pipeline to time out, got %d timeouts: %v", count, err)
+		}
+	}
+}
+
+func TestMetricCollectorStopHonoursContext(t *testing.T) {
+	collector := newMetricCollector(time.Minute, &blockingExporter{blockMetrics: true}, QueueConfig{}.withDefaults(), &diagnostics{logger: discardLogger{}, errorHandler: func(error) {}})
+	collector.start()
+
+	// one batch is exported while the queue of the sender is full, so the final send of stop blocks
+	for range cap(collector.sender.aggsQueue.items) + 1 {
+		collector.sender.sendAggregatedMetrics(&aggregatedMetrics{counterMetrics: []*CounterMessage{{MetricName: "queued", Value: 1}}})
+	}
+	collector.addCounter(&counterEvent{name: "pending", value: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- collector.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the stop to time out, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stop to return once the context is done")
+	}
+}
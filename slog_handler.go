@@ -0,0 +1,170 @@
+package vigilant
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"time"
+)
+
+// slogHandler is a slog.Handler that sends records through the Vigilant log pipeline
+// attributes added with WithAttrs are converted once and reused for every record,
+// groups are flattened into dotted keys (e.g. "http.method")
+type slogHandler struct {
+	client *Client
+	attrs  map[string]string
+	group  string
+}
+
+// NewSlogHandler creates a slog.Handler that sends records through the default client
+//
+// Use this function when you want the standard library logger to ship logs to Vigilant.
+//
+// Example:
+//
+//	slog.SetDefault(slog.New(vigilant.NewSlogHandler()))
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{
+		attrs: make(map[string]string),
+	}
+}
+
+// SlogHandler returns a slog.Handler that sends records through the client
+func (c *Client) SlogHandler() slog.Handler {
+	return &slogHandler{
+		client: c,
+		attrs:  make(map[string]string),
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	client := h.getClient()
+	if client == nil {
+		return false
+	}
+	return client.instance.levelEnabled(slogLevelToLogLevel(level), "")
+}
+
+// Handle converts the record into a log message and captures it
+// attributes stored in the context with WithAttributes are added to the message
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	client := h.getClient()
+	if client == nil {
+		gateNilGlobalInstance(nil)
+		return nil
+	}
+
+	level := slogLevelToLogLevel(record.Level)
+	if !client.instance.levelEnabled(level, "") {
+		return nil
+	}
+
+	attrs := contextAttributes(ctx)
+	maps.Copy(attrs, h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(attrs, h.group, attr)
+		return true
+	})
+
+	log := createLogMessage(level, record.Message, attrs)
+	if log == nil {
+		return nil
+	}
+	if !record.Time.IsZero() {
+		log.Timestamp = record.Time
+	}
+
+	client.instance.captureLog(log)
+	return nil
+}
+
+// WithAttrs returns a new handler with the given attributes bound to every record
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	boundAttrs := maps.Clone(h.attrs)
+	for _, attr := range attrs {
+		addSlogAttr(boundAttrs, h.group, attr)
+	}
+
+	return &slogHandler{
+		client: h.client,
+		attrs:  boundAttrs,
+		group:  h.group,
+	}
+}
+
+// WithGroup returns a new handler that prefixes subsequent attribute keys with the group name
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{
+		client: h.client,
+		attrs:  h.attrs,
+		group:  joinSlogKey(h.group, name),
+	}
+}
+
+// getClient returns the handler's client, falling back to the default client
+func (h *slogHandler) getClient() *Client {
+	if h.client != nil {
+		return h.client
+	}
+	return globalClient.Load()
+}
+
+// addSlogAttr flattens a slog attribute into the given map
+func addSlogAttr(attrs map[string]string, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		// a group without attributes is omitted, as slog handlers are expected to do
+		if len(attr.Value.Group()) == 0 {
+			return
+		}
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = joinSlogKey(prefix, attr.Key)
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			addSlogAttr(attrs, groupPrefix, groupAttr)
+		}
+	case slog.KindTime:
+		attrs[joinSlogKey(prefix, attr.Key)] = attr.Value.Time().Format(time.RFC3339)
+	default:
+		attrs[joinSlogKey(prefix, attr.Key)] = attr.Value.String()
+	}
+}
+
+// joinSlogKey joins a group prefix and a key with a dot
+func joinSlogKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// slogLevelToLogLevel maps a slog level onto the closest Vigilant level
+func slogLevelToLogLevel(level slog.Level) LogLevel {
+	switch {
+	case level >= slog.LevelError:
+		return LEVEL_ERROR
+	case level >= slog.LevelWarn:
+		return LEVEL_WARN
+	case level >= slog.LevelInfo:
+		return LEVEL_INFO
+	case level >= slog.LevelDebug:
+		return LEVEL_DEBUG
+	default:
+		return LEVEL_TRACE
+	}
+}
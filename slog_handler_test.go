@@ -0,0 +1,155 @@
+package vigilant
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+	"testing/slogtest"
+)
+
+// slogAttributes logs through a slog logger backed by a client and returns the attributes of the log sent,
+// without the service attribute of the config
+func slogAttributes(t *testing.T, log func(logger *slog.Logger)) map[string]string {
+	t.Helper()
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).Build())
+	log(slog.New(client.SlogHandler()))
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mux.Lock()
+	defer server.mux.Unlock()
+	if len(server.batches) != 1 || len(server.batches[0].Logs) != 1 {
+		t.Fatalf("expected a single log, got %d batches", len(server.batches))
+	}
+	attrs := server.batches[0].Logs[0].Attributes
+	delete(attrs, "service")
+	return attrs
+}
+
+func TestSlogHandlerGroupsAndAttrs(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		log  func(logger *slog.Logger)
+		want map[string]string
+	}{
+		{
+			name: "nested groups",
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("http").WithGroup("request").Info("msg", "method", "GET")
+			},
+			want: map[string]string{"http.request.method": "GET"},
+		},
+		{
+			name: "attrs bound before and after a group",
+			log: func(logger *slog.Logger) {
+				logger.With("app", "shop").WithGroup("http").With("route", "/pay").Info("msg", slog.Group("response", "status", 200))
+			},
+			want: map[string]string{"app": "shop", "http.route": "/pay", "http.response.status": "200"},
+		},
+		{
+			name: "empty groups are omitted",
+			log: func(logger *slog.Logger) {
+				logger.With("app", "shop").WithGroup("unused").Info("msg", slog.Group("empty"), slog.Group("outer", slog.Group("inner")))
+			},
+			want: map[string]string{"app": "shop"},
+		},
+		{
+			name: "groups without a key are inlined",
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", slog.Group("", "a", 1), slog.Attr{}, slog.Group("g", slog.Attr{}, "b", true))
+			},
+			want: map[string]string{"a": "1", "g.b": "true"},
+		},
+		{
+			name: "group names that are empty are ignored",
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("").Info("msg", "k", "v")
+			},
+			want: map[string]string{"k": "v"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := slogAttributes(t, test.log); !maps.Equal(got, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).WithLevel(LEVEL_INFO).Build())
+	logger := slog.New(client.SlogHandler())
+
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected debug records to be disabled at the INFO level")
+	}
+	logger.Debug("dropped")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	logger.Log(context.Background(), slog.LevelError+4, "fatal")
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mux.Lock()
+	defer server.mux.Unlock()
+	var levels []LogLevel
+	for _, batch := range server.batches {
+		for _, log := range batch.Logs {
+			levels = append(levels, log.Level)
+		}
+	}
+	want := []LogLevel{LEVEL_INFO, LEVEL_WARN, LEVEL_ERROR, LEVEL_ERROR}
+	if len(levels) != len(want) {
+		t.Fatalf("expected %v, got %v", want, levels)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, levels)
+		}
+	}
+}
+
+// slogtest checks the rules of the slog.Handler contract; the records are rebuilt into the nested form it expects
+func TestSlogHandlerConformance(t *testing.T) {
+	var client *Client
+	var server *testServer
+	slogtest.Run(t, func(t *testing.T) slog.Handler {
+		server = newTestServer(t, nil)
+		client = NewClient(testConfig(server).Build())
+		return client.SlogHandler()
+	}, func(t *testing.T) map[string]any {
+		client.Shutdown()
+		server.mux.Lock()
+		defer server.mux.Unlock()
+		log := server.batches[0].Logs[0]
+		result := map[string]any{slog.LevelKey: log.Level, slog.MessageKey: log.Body}
+		// the Vigilant log always has a timestamp, the time of the capture is used for a zero Record.Time
+		if t.Name() != "TestSlogHandlerConformance/zero-time" {
+			result[slog.TimeKey] = log.Timestamp
+		}
+		for key, value := range log.Attributes {
+			if key == "service" {
+				continue
+			}
+			nested := result
+			path := strings.Split(key, ".")
+			for _, group := range path[:len(path)-1] {
+				next, ok := nested[group].(map[string]any)
+				if !ok {
+					next = make(map[string]any)
+					nested[group] = next
+				}
+				nested = next
+			}
+			nested[path[len(path)-1]] = value
+		}
+		return result
+	})
+}
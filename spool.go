@@ -0,0 +1,343 @@
+package vigilant
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSpoolMaxBytes = 100 * 1024 * 1024
+	defaultSpoolMaxAge   = 24 * time.Hour
+	spoolReplayInterval  = 30 * time.Second
+	spoolTempExt         = ".tmp"
+)
+
+// SpoolSegmentExt is the extension of the segment files of a spool directory
+const SpoolSegmentExt = ".seg"
+
+// spool is a directory of batches that could not be sent
+// each batch is stored in its own segment file, named so that sorting the names gives the write order
+// a segment starts with a header line "<crc32> <length> <path>" followed by the batch payload
+type spool struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+	diag     *diagnostics
+
+	mux sync.Mutex
+	seq uint64
+}
+
+// spoolSegment is a segment file in the spool directory
+type spoolSegment struct {
+	name    string
+	size    int64
+	written time.Time
+}
+
+// newSpool creates a new spool, creating the directory if needed
+// the segments that cannot be replayed are reported to diag
+func newSpool(config SpoolConfig, diag *diagnostics) (*spool, error) {
+	config = config.withDefaults()
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &spool{
+		dir:      config.Dir,
+		maxBytes: config.MaxBytes,
+		maxAge:   config.MaxAge,
+		diag:     diag,
+	}
+	s.removeTempFiles()
+
+	return s, nil
+}
+
+// write persists a batch to a new segment and enforces the size and age caps
+// the token of the batch is left out of the segment, it is added back with withSpoolToken on replay
+func (s *spool) write(path string, batchBytes []byte) error {
+	batchBytes, err := withoutSpoolToken(batchBytes)
+	if err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.seq++
+	name := fmt.Sprintf("%020d-%010d%s", time.Now().UnixNano(), s.seq, SpoolSegmentExt)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%08x %d %s\n", crc32.ChecksumIEEE(batchBytes), len(batchBytes), path)
+	buf.Write(batchBytes)
+
+	tempPath := filepath.Join(s.dir, name+spoolTempExt)
+	if err := os.WriteFile(tempPath, buf.Bytes(), 0o600); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if err := os.Rename(tempPath, filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	return s.enforceLimits()
+}
+
+// replay sends the spooled batches in the order they were written
+// a batch is removed once it is sent or once the server rejects it permanently,
+// replay stops at the first failure that is not permanent so the remaining batches keep their order
+func (s *spool) replay(ctx context.Context, send func(ctx context.Context, path string, batchBytes []byte) error) error {
+	s.mux.Lock()
+	segments, err := s.listSegments()
+	s.mux.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		segmentPath := filepath.Join(s.dir, segment.name)
+		if time.Since(segment.written) > s.maxAge {
+			s.remove(segmentPath)
+			continue
+		}
+
+		path, batchBytes, err := readSpoolSegment(segmentPath)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				s.diag.reportError(fmt.Errorf("error reading spool segment %s, discarding it: %w", segment.name, err))
+				s.remove(segmentPath)
+			}
+			continue
+		}
+
+		if err := send(ctx, path, batchBytes); err != nil {
+			if !isPermanentError(err) {
+				return err
+			}
+			s.diag.reportError(fmt.Errorf("error replaying spool segment %s, discarding it: %w", segment.name, err))
+		}
+		s.remove(segmentPath)
+	}
+
+	return nil
+}
+
+// enforceLimits removes expired segments and the oldest segments above the size cap
+// the lock is expected to be held by the caller
+func (s *spool) enforceLimits() error {
+	segments, err := s.listSegments()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, segment := range segments {
+		total += segment.size
+	}
+
+	for _, segment := range segments {
+		if total <= s.maxBytes && time.Since(segment.written) <= s.maxAge {
+			break
+		}
+		os.Remove(filepath.Join(s.dir, segment.name))
+		total -= segment.size
+	}
+
+	return nil
+}
+
+// listSegments returns the segments in the spool directory, oldest first
+// the lock is expected to be held by the caller
+func (s *spool) listSegments() ([]spoolSegment, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]spoolSegment, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != SpoolSegmentExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		segments = append(segments, spoolSegment{
+			name:    entry.Name(),
+			size:    info.Size(),
+			written: parseSpoolSegmentTime(entry.Name(), info.ModTime()),
+		})
+	}
+
+	slices.SortFunc(segments, func(a, b spoolSegment) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return segments, nil
+}
+
+// remove removes a segment file
+func (s *spool) remove(segmentPath string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	os.Remove(segmentPath)
+}
+
+// removeTempFiles removes segments that were being written when the process stopped
+func (s *spool) removeTempFiles() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == spoolTempExt {
+			os.Remove(filepath.Join(s.dir, entry.Name()))
+		}
+	}
+}
+
+// readSpoolSegment reads and validates a segment file, returning the path and the batch payload
+func readSpoolSegment(segmentPath string) (string, []byte, error) {
+	data, err := os.ReadFile(segmentPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return "", nil, fmt.Errorf("missing header")
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return "", nil, fmt.Errorf("malformed header")
+	}
+	checksum, err := strconv.ParseUint(fields[0], 16, 32)
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed checksum")
+	}
+	length, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed length")
+	}
+
+	batchBytes := data[len(header):]
+	if len(batchBytes) != length {
+		return "", nil, fmt.Errorf("truncated payload, expected %d bytes and got %d", length, len(batchBytes))
+	}
+	if crc32.ChecksumIEEE(batchBytes) != uint32(checksum) {
+		return "", nil, fmt.Errorf("checksum mismatch")
+	}
+
+	return fields[2], batchBytes, nil
+}
+
+// ListSpoolSegments returns the paths of the segment files of a spool directory in the order they were written
+func ListSpoolSegments(dir string) ([]string, error) {
+	segments, err := (&spool{dir: dir}).listSegments()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		paths = append(paths, filepath.Join(dir, segment.name))
+	}
+	return paths, nil
+}
+
+// ReadSpoolSegment reads the records of a segment file written by the spool, e.g. to upload it with the replay command
+// it returns an error when the segment is truncated or its checksum does not match
+func ReadSpoolSegment(segmentPath string) ([]*Record, error) {
+	_, batchBytes, err := readSpoolSegment(segmentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch messageBatch
+	if err := json.Unmarshal(batchBytes, &batch); err != nil {
+		return nil, fmt.Errorf("malformed batch: %w", err)
+	}
+
+	var records []*Record
+	for _, log := range batch.Logs {
+		records = append(records, &Record{Log: log})
+	}
+	for _, metric := range batch.Metrics {
+		records = append(records, &Record{Metric: metric})
+	}
+	for _, counter := range batch.MetricsCounters {
+		records = append(records, &Record{Counter: counter})
+	}
+	for _, gauge := range batch.MetricsGauges {
+		records = append(records, &Record{Gauge: gauge})
+	}
+	for _, histogram := range batch.MetricsHistograms {
+		records = append(records, &Record{Histogram: histogram})
+	}
+	return records, nil
+}
+
+// withoutSpoolToken removes the token field of a batch so the API token is never written to disk
+func withoutSpoolToken(batchBytes []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(batchBytes, &fields); err != nil {
+		return nil, fmt.Errorf("malformed batch: %w", err)
+	}
+	if _, ok := fields["token"]; !ok {
+		return batchBytes, nil
+	}
+	delete(fields, "token")
+	return json.Marshal(fields)
+}
+
+// withSpoolToken sets the token field of a spooled batch
+// a batch that cannot be decoded is returned as it is, so the server rejects it and the segment is discarded
+func withSpoolToken(batchBytes []byte, token string) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(batchBytes, &fields); err != nil {
+		return batchBytes
+	}
+	tokenBytes, err := json.Marshal(token)
+	if err != nil {
+		return batchBytes
+	}
+	fields["token"] = tokenBytes
+	withToken, err := json.Marshal(fields)
+	if err != nil {
+		return batchBytes
+	}
+	return withToken
+}
+
+// parseSpoolSegmentTime returns the write time encoded in a segment name
+func parseSpoolSegmentTime(name string, fallback time.Time) time.Time {
+	prefix, _, found := strings.Cut(name, "-")
+	if !found {
+		return fallback
+	}
+	nanos, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,220 @@
+package vigilant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// spooledLogCount returns the number of logs in the segments of the spool directory
+func spooledLogCount(t *testing.T, dir string) int {
+	t.Helper()
+	s, err := newSpool(SpoolConfig{Dir: dir}, defaultDiagnostics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments, err := s.listSegments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, segment := range segments {
+		_, batchBytes, err := readSpoolSegment(filepath.Join(dir, segment.name))
+		if err != nil {
+			t.Fatalf("segment %s: %v", segment.name, err)
+		}
+		var batch messageBatch
+		if err := json.Unmarshal(batchBytes, &batch); err != nil {
+			t.Fatal(err)
+		}
+		count += len(batch.Logs)
+	}
+	return count
+}
+
+func TestSpoolKeepsPendingLogsOnShutdownTimeout(t *testing.T) {
+	dir := t.TempDir()
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+		time.Sleep(20 * time.Millisecond)
+		return http.StatusServiceUnavailable
+	})
+
+	client := NewClient(testConfig(server).
+		WithRetry(RetryConfig{MaxAttempts: 100, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}).
+		WithSpool(SpoolConfig{Dir: dir}).
+		Build())
+	for range 250 {
+		client.LogInfo("pending")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.ShutdownContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the shutdown to time out, got %v", err)
+	}
+
+	if count := spooledLogCount(t, dir); count != 250 {
+		t.Fatalf("expected 250 spooled logs, got %d", count)
+	}
+}
+
+func TestSpoolReplaysOnStart(t *testing.T) {
+	dir := t.TempDir()
+	down := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+		return http.StatusServiceUnavailable
+	})
+	client := NewClient(testConfig(down).WithSpool(SpoolConfig{Dir: dir}).Build())
+	for range 10 {
+		client.LogInfo("spooled")
+	}
+	client.Shutdown()
+	if count := spooledLogCount(t, dir); count != 10 {
+		t.Fatalf("expected 10 spooled logs, got %d", count)
+	}
+
+	up := newTestServer(t, nil)
+	client = NewClient(testConfig(up).WithSpool(SpoolConfig{Dir: dir}).Build())
+	defer client.Shutdown()
+	waitFor(t, 5*time.Second, func() bool { return up.logCount() == 10 })
+	waitFor(t, 5*time.Second, func() bool { return spooledLogCount(t, dir) == 0 })
+}
+
+func TestSpoolKeepsTokenOffDisk(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spool")
+	down := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+		return http.StatusServiceUnavailable
+	})
+	client := NewClient(testConfig(down).WithSpool(SpoolConfig{Dir: dir}).Build())
+	client.LogInfo("spooled")
+	client.Shutdown()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o700 {
+		t.Errorf("expected the spool directory mode to be 0700, got %o", mode)
+	}
+	segments, err := ListSpoolSegments(dir)
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("expected 1 segment, got %v %v", segments, err)
+	}
+	info, err = os.Stat(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("expected the segment mode to be 0600, got %o", mode)
+	}
+	data, err := os.ReadFile(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "tk_test") || strings.Contains(string(data), `"token"`) {
+		t.Fatalf("expected the segment to leave out the token, got %s", data)
+	}
+
+	up := newTestServer(t, nil)
+	client = NewClient(testConfig(up).WithSpool(SpoolConfig{Dir: dir}).Build())
+	defer client.Shutdown()
+	waitFor(t, 5*time.Second, func() bool { return up.logCount() == 1 })
+
+	up.mux.Lock()
+	defer up.mux.Unlock()
+	if token := up.batches[0].Token; token != "tk_test" {
+		t.Fatalf("expected the replayed batch to carry the token, got %q", token)
+	}
+}
+
+func TestSpoolDiscardsRejectedAndCorruptSegments(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(SpoolConfig{Dir: dir}, &diagnostics{logger: discardLogger{}, errorHandler: func(error) {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.write(logEndpoint, []byte(`{"logs":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000001-0000000001"+SpoolSegmentExt), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sends := 0
+	err = s.replay(context.Background(), func(ctx context.Context, path string, batchBytes []byte) error {
+		sends++
+		return &StatusError{StatusCode: http.StatusBadRequest}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sends != 1 {
+		t.Fatalf("expected 1 send, got %d", sends)
+	}
+	if segments, _ := s.listSegments(); len(segments) != 0 {
+		t.Fatalf("expected an empty spool, got %d segments", len(segments))
+	}
+}
+
+func TestSpoolReplayStopsOnCancelledSend(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(SpoolConfig{Dir: dir}, defaultDiagnostics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := s.write(logEndpoint, []byte(`{"logs":[]}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = s.replay(context.Background(), func(ctx context.Context, path string, batchBytes []byte) error {
+		return context.Canceled
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if segments, _ := s.listSegments(); len(segments) != 2 {
+		t.Fatalf("expected both segments to be kept, got %d", len(segments))
+	}
+}
+
+func TestReadSpoolSegment(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(SpoolConfig{Dir: dir}, defaultDiagnostics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := `{"token":"tk_test","logs":[{"body":"a"},{"body":"b"}],"metrics_counters":[{"metric_name":"c","value":1}]}`
+	if err := s.write(logEndpoint, []byte(batch)); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := ListSpoolSegments(dir)
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("expected 1 segment, got %v %v", segments, err)
+	}
+	records, err := ReadSpoolSegment(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0].Log.Body != "a" || records[1].Log.Body != "b" || records[2].Counter.MetricName != "c" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+
+	data, err := os.ReadFile(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(segments[0], data[:len(data)-1], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSpoolSegment(segments[0]); err == nil {
+		t.Fatal("expected an error for a truncated segment")
+	}
+}
@@ -0,0 +1,165 @@
+package vigilant
+
+import (
+	"time"
+)
+
+// StatsSnapshot is the health of the pipelines of a client at a point in time
+// the counts are totals since the client was created
+type StatsSnapshot struct {
+	// Logs is the pipeline of the logs
+	Logs PipelineStats
+
+	// Metrics is the pipeline of the metrics from MetricEvent
+	Metrics PipelineStats
+
+	// Collector is the pipeline of the counters, gauges and histograms,
+	// its enqueued, dropped and queue counts are events, its sent and failed counts are aggregated series
+	Collector PipelineStats
+}
+
+// PipelineStats is the health of a pipeline
+type PipelineStats struct {
+	// Enqueued is the number of items added to the queue
+	Enqueued uint64
+
+	// Dropped is the number of items dropped by the overflow policy of the queue
+	Dropped uint64
+
+	// Sent is the number of items exported
+	Sent uint64
+
+	// Failed is the number of items whose export failed, after the retries
+	Failed uint64
+
+	// Batches is the number of batches exported
+	Batches uint64
+
+	// FailedBatches is the number of batches whose export failed, after the retries
+	FailedBatches uint64
+
+	// Retries is the number of times the transport retried a batch
+	Retries uint64
+
+	// QueueDepth is the number of items waiting in the queue
+	QueueDepth int
+
+	// QueueCapacity is the number of items the queue holds
+	QueueCapacity int
+
+	// MaxBatchSize is the largest batch exported so far
+	MaxBatchSize int
+
+	// AvgBatchSize is the average number of items in a batch
+	AvgBatchSize float64
+
+	// LastSendLatency is how long the last export took, including its retries
+	LastSendLatency time.Duration
+
+	// AvgSendLatency is the average time an export took
+	AvgSendLatency time.Duration
+
+	// MaxSendLatency is the longest time an export took
+	MaxSendLatency time.Duration
+
+	// LastError is the error of the last failed export, nil if no export failed
+	LastError error
+
+	// LastErrorAt is when the last export failed
+	LastErrorAt time.Time
+}
+
+// Stats returns the health of the pipelines of the default client
+func Stats() StatsSnapshot {
+	client := globalClient.Load()
+	if client == nil {
+		return StatsSnapshot{}
+	}
+	return client.Stats()
+}
+
+// stats returns the health of the pipelines of the instance
+func (a *instance) stats() StatsSnapshot {
+	collector := a.metricCollector
+
+	logs := a.logBatcher.sends.snapshot()
+	addQueueStats(&logs, a.logBatcher.logQueue)
+
+	metrics := a.metricBatcher.sends.snapshot()
+	addQueueStats(&metrics, a.metricBatcher.metricQueue)
+
+	collected := collector.sender.sends.snapshot()
+	addQueueStats(&collected, collector.counterEvents)
+	addQueueStats(&collected, collector.gaugeEvents)
+	addQueueStats(&collected, collector.histogramEvents)
+
+	return StatsSnapshot{
+		Logs:      logs,
+		Metrics:   metrics,
+		Collector: collected,
+	}
+}
+
+// snapshot returns the send counts as pipeline stats without the queue counts
+func (s *sendStats) snapshot() PipelineStats {
+	stats := PipelineStats{
+		Sent:            s.sentItems.Load(),
+		Failed:          s.failedItems.Load(),
+		Batches:         s.sent.Load(),
+		FailedBatches:   s.failed.Load(),
+		Retries:         s.retries.Load(),
+		MaxBatchSize:    int(s.maxBatch.Load()),
+		LastSendLatency: time.Duration(s.lastLatency.Load()),
+		MaxSendLatency:  time.Duration(s.maxLatency.Load()),
+	}
+	if exports := stats.Batches + stats.FailedBatches; exports > 0 {
+		stats.AvgBatchSize = float64(stats.Sent+stats.Failed) / float64(exports)
+		stats.AvgSendLatency = time.Duration(s.latency.Load() / int64(exports))
+	}
+	if failure := s.lastFail.Load(); failure != nil {
+		stats.LastError = failure.err
+		stats.LastErrorAt = failure.at
+	}
+	return stats
+}
+
+// addQueueStats adds the counts of a queue to the pipeline stats
+func addQueueStats[T any](stats *PipelineStats, q *queue[T]) {
+	stats.Enqueued += q.enqueuedCount()
+	stats.Dropped += q.droppedCount()
+	stats.QueueDepth += q.depth()
+	stats.QueueCapacity += cap(q.items)
+}
+
+// telemetryEvents returns the stats of the instance as gauges tagged with the pipeline name and the global attributes
+// they are added to the collector on every interval when self-telemetry is enabled
+func (a *instance) telemetryEvents() []*gaugeEvent {
+	stats := a.stats()
+	var events []*gaugeEvent
+	for _, pipeline := range []struct {
+		name  string
+		stats PipelineStats
+	}{
+		{PipelineLogs, stats.Logs},
+		{PipelineMetrics, stats.Metrics},
+		{PipelineCollector, stats.Collector},
+	} {
+		tag := Tag("pipeline", pipeline.name)
+		s := pipeline.stats
+		for name, value := range map[string]float64{
+			"vigilant_sdk_enqueued":            float64(s.Enqueued),
+			"vigilant_sdk_dropped":             float64(s.Dropped),
+			"vigilant_sdk_sent":                float64(s.Sent),
+			"vigilant_sdk_failed":              float64(s.Failed),
+			"vigilant_sdk_retries":             float64(s.Retries),
+			"vigilant_sdk_queue_depth":         float64(s.QueueDepth),
+			"vigilant_sdk_batch_size_avg":      s.AvgBatchSize,
+			"vigilant_sdk_send_latency_avg_ms": milliseconds(s.AvgSendLatency),
+		} {
+			event := createGaugeEvent(name, value, GaugeModeSet, tag)
+			a.addGlobalAttributes(event.tags)
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,133 @@
+package vigilant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStatsCountsSentItems(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).WithLogQueue(QueueConfig{Capacity: 50}).Build())
+	defer client.Shutdown()
+
+	for range 5 {
+		client.LogInfo("log")
+	}
+	for range 3 {
+		client.MetricEvent("event", 1)
+	}
+	client.MetricCounter("counter", 1, Tag("route", "a"))
+	client.MetricCounter("counter", 2, Tag("route", "a"))
+	client.MetricCounter("counter", 1, Tag("route", "b"))
+	if err := client.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := client.Stats()
+	logs := stats.Logs
+	if logs.Enqueued != 5 || logs.Sent != 5 || logs.Failed != 0 || logs.Dropped != 0 || logs.QueueDepth != 0 {
+		t.Errorf("unexpected log counts %+v", logs)
+	}
+	if logs.Batches == 0 || logs.MaxBatchSize == 0 || logs.AvgBatchSize == 0 || logs.QueueCapacity != 50 {
+		t.Errorf("unexpected log batches %+v", logs)
+	}
+	if logs.LastSendLatency == 0 || logs.MaxSendLatency < logs.LastSendLatency || logs.LastError != nil {
+		t.Errorf("unexpected log sends %+v", logs)
+	}
+	if metrics := stats.Metrics; metrics.Enqueued != 3 || metrics.Sent != 3 || metrics.Failed != 0 {
+		t.Errorf("unexpected metric counts %+v", metrics)
+	}
+	// the collector counts the events it receives and the series it sends
+	if collector := stats.Collector; collector.Enqueued != 3 || collector.Sent != 2 || collector.Failed != 0 {
+		t.Errorf("unexpected collector counts %+v", collector)
+	}
+}
+
+func TestStatsCountsFailedItems(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) int {
+		return http.StatusBadRequest
+	})
+	client := NewClient(testConfig(server).Build())
+	defer client.Shutdown()
+
+	before := time.Now()
+	for range 3 {
+		client.LogInfo("rejected")
+	}
+	if err := client.Flush(context.Background()); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+
+	logs := client.Stats().Logs
+	if logs.Enqueued != 3 || logs.Sent != 0 || logs.Failed != 3 || logs.FailedBatches != 1 || logs.Retries != 0 {
+		t.Errorf("unexpected log counts %+v", logs)
+	}
+	var statusErr *StatusError
+	if !errors.As(logs.LastError, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected the last error to be the rejection, got %v", logs.LastError)
+	}
+	if logs.LastErrorAt.Before(before) {
+		t.Errorf("expected the time of the last error, got %v", logs.LastErrorAt)
+	}
+}
+
+func TestSelfTelemetryGauges(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).WithSelfTelemetry(true).WithAttributes(String("region", "eu")).Build())
+	client.LogInfo("log")
+	if err := client.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client.instance.metricCollector.sendMetricsForInterval(time.Now())
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mux.Lock()
+	defer server.mux.Unlock()
+	gauges := make(map[string]*GaugeMessage)
+	for _, batch := range server.batches {
+		for _, gauge := range batch.MetricsGauges {
+			gauges[gauge.Tags["pipeline"]+" "+gauge.MetricName] = gauge
+		}
+	}
+	names := []string{
+		"vigilant_sdk_enqueued", "vigilant_sdk_dropped", "vigilant_sdk_sent", "vigilant_sdk_failed", "vigilant_sdk_retries",
+		"vigilant_sdk_queue_depth", "vigilant_sdk_batch_size_avg", "vigilant_sdk_send_latency_avg_ms",
+	}
+	for _, pipeline := range []string{PipelineLogs, PipelineMetrics, PipelineCollector} {
+		for _, name := range names {
+			gauge, ok := gauges[pipeline+" "+name]
+			if !ok {
+				t.Errorf("missing the %s gauge of the %s pipeline", name, pipeline)
+				continue
+			}
+			if gauge.Tags["service"] != "test" || gauge.Tags["region"] != "eu" {
+				t.Errorf("expected the global attributes on %s, got %v", name, gauge.Tags)
+			}
+		}
+	}
+	if gauge := gauges[PipelineLogs+" vigilant_sdk_sent"]; gauge == nil || gauge.Value != 1 {
+		t.Errorf("expected the sent gauge of the logs to be 1, got %+v", gauge)
+	}
+}
+
+func TestSelfTelemetryDisabledByDefault(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).Build())
+	client.instance.metricCollector.sendMetricsForInterval(time.Now())
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mux.Lock()
+	defer server.mux.Unlock()
+	if i := slices.IndexFunc(server.batches, func(batch *messageBatch) bool { return len(batch.MetricsGauges) > 0 }); i != -1 {
+		t.Fatalf("expected no gauges without self-telemetry, got %v", server.batches[i].MetricsGauges)
+	}
+}
@@ -31,47 +31,47 @@ const (
 // messageBatch represents a batch of logs
 type messageBatch struct {
 	Token             string              `json:"token"`
-	Logs              []*logMessage       `json:"logs,omitempty"`
-	Metrics           []*metricMessage    `json:"metrics,omitempty"`
-	MetricsCounters   []*counterMessage   `json:"metrics_counters,omitempty"`
-	MetricsGauges     []*gaugeMessage     `json:"metrics_gauges,omitempty"`
-	MetricsHistograms []*histogramMessage `json:"metrics_histograms,omitempty"`
+	Logs              []*LogMessage       `json:"logs,omitempty"`
+	Metrics           []*MetricMessage    `json:"metrics,omitempty"`
+	MetricsCounters   []*CounterMessage   `json:"metrics_counters,omitempty"`
+	MetricsGauges     []*GaugeMessage     `json:"metrics_gauges,omitempty"`
+	MetricsHistograms []*HistogramMessage `json:"metrics_histograms,omitempty"`
 }
 
-// logMessage represents a log message
-type logMessage struct {
+// LogMessage represents a log message
+type LogMessage struct {
 	Timestamp  time.Time         `json:"timestamp"`
 	Body       string            `json:"body"`
 	Level      LogLevel          `json:"level"`
 	Attributes map[string]string `json:"attributes"`
 }
 
-// metricMessage represents a metric message
-type metricMessage struct {
+// MetricMessage represents a metric message
+type MetricMessage struct {
 	Timestamp  time.Time         `json:"timestamp"`
 	Name       string            `json:"name"`
 	Value      float64           `json:"value"`
 	Attributes map[string]string `json:"attributes"`
 }
 
-// counterMessage represents a counter metric message
-type counterMessage struct {
+// CounterMessage represents a counter metric message
+type CounterMessage struct {
 	Timestamp  time.Time         `json:"timestamp"`
 	MetricName string            `json:"metric_name"`
 	Value      float64           `json:"value"`
 	Tags       map[string]string `json:"tags"`
 }
 
-// gaugeMessage represents a gauge metric message
-type gaugeMessage struct {
+// GaugeMessage represents a gauge metric message
+type GaugeMessage struct {
 	Timestamp  time.Time         `json:"timestamp"`
 	MetricName string            `json:"metric_name"`
 	Value      float64           `json:"value"`
 	Tags       map[string]string `json:"tags"`
 }
 
-// histogramMessage represents a histogram metric message
-type histogramMessage struct {
+// HistogramMessage represents a histogram metric message
+type HistogramMessage struct {
 	Timestamp  time.Time         `json:"timestamp"`
 	MetricName string            `json:"metric_name"`
 	Tags       map[string]string `json:"tags"`
@@ -80,17 +80,17 @@ type histogramMessage struct {
 
 // aggregatedMetrics represents a collection of counter and gauge metrics
 type aggregatedMetrics struct {
-	counterMetrics   []*counterMessage
-	gaugeMetrics     []*gaugeMessage
-	histogramMetrics []*histogramMessage
+	counterMetrics   []*CounterMessage
+	gaugeMetrics     []*GaugeMessage
+	histogramMetrics []*HistogramMessage
 }
 
 // newAggregatedMetrics creates a new aggregatedMetrics
 func newAggregatedMetrics() *aggregatedMetrics {
 	return &aggregatedMetrics{
-		counterMetrics:   make([]*counterMessage, 0),
-		gaugeMetrics:     make([]*gaugeMessage, 0),
-		histogramMetrics: make([]*histogramMessage, 0),
+		counterMetrics:   make([]*CounterMessage, 0),
+		gaugeMetrics:     make([]*GaugeMessage, 0),
+		histogramMetrics: make([]*HistogramMessage, 0),
 	}
 }
 
@@ -3,6 +3,7 @@ package vigilant
 import (
 	"bytes"
 	"fmt"
+	"sync/atomic"
 	"time"
 )
 
@@ -76,25 +77,24 @@ func getLevelInt(level LogLevel) int {
 	}
 }
 
-// globalEmitNilError is a flag to emit the nil error only once
-var globalEmitNilError = true
+// globalNilErrorEmitted is a flag to emit the nil error only once
+var globalNilErrorEmitted atomic.Bool
 
-// gateNilGlobalInstance checks if the Vigilant instance is nil
-func gateNilGlobalInstance() bool {
-	if globalInstance != nil {
+// gateNilGlobalInstance checks if the given default Vigilant client is nil
+func gateNilGlobalInstance(client *Client) bool {
+	if client != nil {
 		return false
 	}
-	if globalEmitNilError {
-		fmt.Printf("\n[ERROR] Vigilant is not initialized.\n\tPlease call vigilant.Init() before using Vigilant.\n\tDocs: https://docs.vigilant.run/\n")
-		globalEmitNilError = false
+	if globalNilErrorEmitted.CompareAndSwap(false, true) {
+		packageDiagnostics().logf("\n[ERROR] Vigilant is not initialized.\n\tPlease call vigilant.Init() before using Vigilant.\n\tDocs: https://docs.vigilant.run/\n")
 	}
 	return true
 }
 
 // createLogMessage creates a log message from the given parameters
-func createLogMessage(level LogLevel, message string, attributes map[string]string) *logMessage {
+func createLogMessage(level LogLevel, message string, attributes map[string]string) *LogMessage {
 	deduplicatedAttributes := deduplicateAttributes(attributes)
-	return &logMessage{
+	return &LogMessage{
 		Timestamp:  time.Now(),
 		Level:      level,
 		Body:       message,
@@ -103,9 +103,9 @@ func createLogMessage(level LogLevel, message string, attributes map[string]stri
 }
 
 // createMetricMessage creates a metric message from the given parameters
-func createMetricMessage(name string, value float64, tags ...MetricTag) *metricMessage {
+func createMetricMessage(name string, value float64, tags ...MetricTag) *MetricMessage {
 	deduplicatedAttributes := deduplicateTags(tags)
-	return &metricMessage{
+	return &MetricMessage{
 		Timestamp:  time.Now(),
 		Name:       name,
 		Value:      value,
@@ -0,0 +1,162 @@
+package vigilant
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	tokenPrefix = "tk_"
+
+	// placeholderToken is the token of the default config, it is not the token of any project
+	placeholderToken = "tk_1234567890"
+)
+
+var (
+	// ErrAlreadyInitialized is returned by InitE when the Vigilant instance is already initialized
+	ErrAlreadyInitialized = errors.New("vigilant is already initialized, call Shutdown first")
+
+	// ErrInvalidConfig is the error every ConfigError matches with errors.Is
+	ErrInvalidConfig = errors.New("invalid vigilant config")
+
+	// errNilConfig is the error of a nil config
+	errNilConfig = &ConfigError{Field: "config", Reason: "config is nil"}
+)
+
+// ConfigError describes an invalid field of a VigilantConfig
+type ConfigError struct {
+	// Field is the name of the invalid field, e.g. "Endpoint" or `LoggerLevels["db"]`
+	Field string
+
+	// Value is the invalid value, it is empty for the token so it is never printed
+	Value string
+
+	// Reason explains what is wrong with the value
+	Reason string
+}
+
+// Error returns the string representation of the error
+func (e *ConfigError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// Unwrap returns ErrInvalidConfig
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Validate checks the config, the returned error joins a *ConfigError for every invalid field
+// the token and endpoint are not checked for noop configs since nothing is sent
+// the placeholder token of the default config is only rejected when the config sends to Vigilant
+func (c *VigilantConfig) Validate() error {
+	if c == nil {
+		return errNilConfig
+	}
+
+	var errs []error
+	if c.Name == "" {
+		errs = append(errs, &ConfigError{Field: "Name", Reason: "name is empty"})
+	}
+	errs = append(errs, validateLevel("Level", c.Level))
+	for _, name := range slices.Sorted(maps.Keys(c.LoggerLevels)) {
+		errs = append(errs, validateLevel(loggerLevelField(name), c.LoggerLevels[name]))
+	}
+	if !c.Noop {
+		errs = append(errs, validateToken(c.Token), validateEndpoint(c.Endpoint))
+		if c.Token == placeholderToken && c.usesVigilantExporter() {
+			errs = append(errs, &ConfigError{Field: "Token", Reason: "token is the placeholder of the default config, set the token of your project"})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Attributes)) {
+		errs = append(errs, validateAttributeKey(key))
+	}
+	return errors.Join(errs...)
+}
+
+// usesVigilantExporter reports whether the client of the config creates a Vigilant exporter with its token
+// exporters set on the config were created with their own config
+func (c *VigilantConfig) usesVigilantExporter() bool {
+	if len(c.Exporters) > 0 {
+		return false
+	}
+	if len(c.fileExporters) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(c.fileExporters, func(exporter fileExporterConfig) bool {
+		return exporter.Type == fileExporterVigilant
+	})
+}
+
+// validateLevel checks that the level can be parsed with ParseLevel
+func validateLevel(field string, level LogLevel) error {
+	if _, err := ParseLevel(string(level)); err != nil {
+		return &ConfigError{Field: field, Value: string(level), Reason: "level must be one of TRACE, DEBUG, INFO, WARNING or ERROR"}
+	}
+	return nil
+}
+
+// validateToken checks the shape of an API token
+func validateToken(token string) error {
+	switch {
+	case token == "":
+		return &ConfigError{Field: "Token", Reason: "token is empty"}
+	case !strings.HasPrefix(token, tokenPrefix) || len(token) == len(tokenPrefix):
+		return &ConfigError{Field: "Token", Reason: "token must start with " + tokenPrefix}
+	case strings.IndexFunc(token, unicode.IsSpace) != -1:
+		return &ConfigError{Field: "Token", Reason: "token contains whitespace"}
+	}
+	return nil
+}
+
+// MaskToken hides the middle of the token so it can be shown to operators, e.g. in logs or a status page
+// tokens of 8 characters or less are hidden entirely
+func MaskToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + strings.Repeat("*", len(token)-8) + token[len(token)-4:]
+}
+
+// validateEndpoint checks that the endpoint is a host with an optional port
+func validateEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return &ConfigError{Field: "Endpoint", Reason: "endpoint is empty"}
+	}
+	if strings.Contains(endpoint, "://") {
+		return &ConfigError{Field: "Endpoint", Value: endpoint, Reason: "endpoint must not have a scheme, use Insecure to select HTTP"}
+	}
+
+	parsed, err := url.Parse("http://" + endpoint)
+	if err != nil || parsed.Hostname() == "" || parsed.User != nil {
+		return &ConfigError{Field: "Endpoint", Value: endpoint, Reason: "endpoint must be a host with an optional port"}
+	}
+	if parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return &ConfigError{Field: "Endpoint", Value: endpoint, Reason: "endpoint must not have a path"}
+	}
+	if port := parsed.Port(); port != "" {
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			return &ConfigError{Field: "Endpoint", Value: endpoint, Reason: "endpoint port is invalid"}
+		}
+	}
+	return nil
+}
+
+// validateAttributeKey checks that an attribute key is not empty and has no whitespace
+func validateAttributeKey(key string) error {
+	if key == "" {
+		return &ConfigError{Field: "Attributes", Reason: "attribute key is empty"}
+	}
+	if strings.IndexFunc(key, unicode.IsSpace) != -1 {
+		return &ConfigError{Field: "Attributes", Value: key, Reason: "attribute key contains whitespace"}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package vigilant
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// configErrorFields returns the fields of the config errors joined in err
+func configErrorFields(err error) []string {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	var fields []string
+	for _, err := range errs {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			fields = append(fields, configErr.Field)
+		}
+	}
+	return fields
+}
+
+func TestValidateRejectsPlaceholderToken(t *testing.T) {
+	err := NewConfigBuilder().Build().Validate()
+	if fields := configErrorFields(err); len(fields) != 1 || fields[0] != "Token" {
+		t.Fatalf("expected the placeholder token to be rejected, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "placeholder") {
+		t.Fatalf("expected the error to name the placeholder, got %v", err)
+	}
+
+	if err := NewConfigBuilder().WithNoop(true).Build().Validate(); err != nil {
+		t.Fatalf("expected a noop config to be valid, got %v", err)
+	}
+	if err := NewConfigBuilder().WithExporters(NewMultiExporter()).Build().Validate(); err != nil {
+		t.Fatalf("expected a config with its own exporters to be valid, got %v", err)
+	}
+}
+
+func TestValidatePlaceholderTokenWithConfigFileExporters(t *testing.T) {
+	output := filepath.ToSlash(filepath.Join(t.TempDir(), "out.ndjson"))
+	for _, test := range []struct {
+		exporters string
+		valid     bool
+	}{
+		{`[{"type": "file", "path": "` + output + `"}]`, true},
+		{`[{"type": "file", "path": "` + output + `"}, {"type": "vigilant"}]`, false},
+	} {
+		builder, err := LoadConfigFile(writeConfigFile(t, `{"exporters": `+test.exporters+`}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := builder.Build().Validate(); (err == nil) != test.valid {
+			t.Errorf("exporters %s: expected valid=%v, got %v", test.exporters, test.valid, err)
+		}
+	}
+}
+
+func TestValidateNamesInvalidLoggerLevel(t *testing.T) {
+	config := NewConfigBuilder().WithToken("tk_test").WithLoggerLevels(map[string]LogLevel{
+		"db.pool": "VERBOSE",
+		"http":    LEVEL_WARN,
+	}).Build()
+
+	err := config.Validate()
+	if fields := configErrorFields(err); len(fields) != 1 || fields[0] != `LoggerLevels["db.pool"]` {
+		t.Fatalf(`expected an error for LoggerLevels["db.pool"], got %v`, err)
+	}
+}
+
+func TestValidateReportsEveryInvalidField(t *testing.T) {
+	config := NewConfigBuilder().
+		WithName("").
+		WithLevel("LOUD").
+		WithToken("abc").
+		WithEndpoint("https://ingress.vigilant.run/api").
+		WithAttributes(String("bad key", "x")).
+		Build()
+
+	err := config.Validate()
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	want := []string{"Name", "Level", "Token", "Endpoint", "Attributes"}
+	if fields := configErrorFields(err); strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected errors for %v, got %v", want, fields)
+	}
+	if strings.Contains(err.Error(), "abc") {
+		t.Fatalf("expected the token to be left out of the error, got %v", err)
+	}
+}
+
+func TestInitNilWhileRunning(t *testing.T) {
+	server := newTestServer(t, nil)
+	if err := InitE(testConfig(server).Build()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+
+	Init(nil)
+	if err := InitE(nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestNilConfig(t *testing.T) {
+	resetPackageSettings(t)
+	SetInternalLogger(discardLogger{})
+	var mux sync.Mutex
+	var errs []error
+	SetErrorHandler(func(err error) {
+		mux.Lock()
+		errs = append(errs, err)
+		mux.Unlock()
+	})
+
+	Init(nil)
+	if globalClient.Load() != nil {
+		t.Fatal("expected Init(nil) to leave Vigilant uninitialized")
+	}
+	LogInfo("ignored")
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitE(nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	client := NewClient(nil)
+	client.LogInfo("ignored")
+	client.MetricEvent("ignored", 1)
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(errs) != 2 || !errors.Is(errs[0], ErrInvalidConfig) || !errors.Is(errs[1], ErrInvalidConfig) {
+		t.Fatalf("expected Init and NewClient to report the nil config, got %v", errs)
+	}
+}
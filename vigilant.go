@@ -1,42 +1,115 @@
 package vigilant
 
 import (
+	"context"
+	"errors"
 	"maps"
-	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// globalInstance is the global Vigilant instance
-var globalInstance *instance
+// globalClient is the default Vigilant client used by the package-level functions
+var globalClient atomic.Pointer[Client]
+
+// globalClientMux serializes Init and Shutdown
+var globalClientMux sync.Mutex
 
 // Init initializes the Vigilant instance, it should be called once when the program is starting
 // Before calling this, all other Vigilant functions will be noops
+// Init can be called again after Shutdown to start a new instance, a call while an instance is running is ignored
+// a nil config is reported and ignored, use InitE to validate the config and get the errors
 func Init(config *VigilantConfig) {
-	if globalInstance != nil {
+	globalClientMux.Lock()
+	defer globalClientMux.Unlock()
+
+	if globalClient.Load() != nil {
+		packageDiagnostics().logf("%v, ignoring Init", ErrAlreadyInitialized)
+		return
+	}
+	if config == nil {
+		packageDiagnostics().reportError(errNilConfig)
 		return
 	}
-	globalInstance = newVigilant(config)
-	globalInstance.start()
+	client := NewClient(config)
+	globalClient.Store(client)
+	globalDiagnostics.Store(client.instance.diag)
+}
+
+// InitE initializes the Vigilant instance like Init, validating the config first
+// it returns the errors of Validate for an invalid config, and ErrAlreadyInitialized when an instance is running
+func InitE(config *VigilantConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	globalClientMux.Lock()
+	defer globalClientMux.Unlock()
+
+	if globalClient.Load() != nil {
+		return ErrAlreadyInitialized
+	}
+	client := NewClient(config)
+	globalClient.Store(client)
+	globalDiagnostics.Store(client.instance.diag)
+	return nil
 }
 
 // Shutdown shuts down the Vigilant instance, it should be called once when the program is shutting down
+// logs and metrics captured concurrently with Shutdown are either sent or discarded
 func Shutdown() error {
-	if globalInstance == nil {
+	return ShutdownContext(context.Background())
+}
+
+// ShutdownContext shuts down the Vigilant instance like Shutdown, giving up on the remaining sends when the context is done
+// the returned error joins the sends that failed or were cancelled
+func ShutdownContext(ctx context.Context) error {
+	globalClientMux.Lock()
+	defer globalClientMux.Unlock()
+
+	client := globalClient.Swap(nil)
+	if client == nil {
+		return nil
+	}
+	return client.ShutdownContext(ctx)
+}
+
+// Flush sends the logs and metrics the Vigilant instance holds without shutting it down
+// it returns when everything is sent or the context is done, joining the sends that failed
+func Flush(ctx context.Context) error {
+	client := globalClient.Load()
+	if client == nil {
 		return nil
 	}
-	return globalInstance.shutdown()
+	return client.Flush(ctx)
+}
+
+// Dropped returns the number of items each pipeline of the default client dropped because its queue was full
+func Dropped() DroppedCounts {
+	client := globalClient.Load()
+	if client == nil {
+		return DroppedCounts{}
+	}
+	return client.Dropped()
 }
 
 // instance is the internal representation of the Vigilant instance
 // it handles the sending of logs and metrics to the server
 type instance struct {
 	name        string
-	level       LogLevel
+	endpoint    string
 	token       string
 	passthrough bool
 	noop        bool
 
+	state atomic.Int32
+
+	diag *diagnostics
+
+	levels    atomic.Pointer[levelState]
+	levelsMux sync.Mutex
+
+	exporter        Exporter
 	logBatcher      *logBatcher
 	metricBatcher   *metricBatcher
 	metricCollector *metricCollector
@@ -47,60 +120,133 @@ type instance struct {
 
 // newVigilant creates a new Vigilant instance from the given config
 func newVigilant(config *VigilantConfig) *instance {
+	diag := newDiagnostics(config)
+	exporter := newExporter(config)
 	logBatcher := newLogBatcher(
-		config.Token,
-		getEndpoint(config),
-		&http.Client{},
+		exporter,
+		config.LogQueue,
+		config.Batch,
+		diag,
 	)
 	metricBatcher := newMetricBatcher(
-		config.Token,
-		getEndpoint(config),
-		&http.Client{},
+		exporter,
+		config.MetricQueue,
+		config.Batch,
+		diag,
 	)
 	metricCollector := newMetricCollector(
 		time.Minute,
-		config.Token,
-		getEndpoint(config),
-		&http.Client{},
+		exporter,
+		config.CollectorQueue,
+		diag,
 	)
-	return &instance{
+	instance := &instance{
 		name:            config.Name,
-		level:           config.Level,
+		endpoint:        config.EndpointURL(),
 		token:           config.Token,
 		passthrough:     config.Passthrough,
 		noop:            config.Noop,
+		diag:            diag,
+		exporter:        exporter,
 		logBatcher:      logBatcher,
 		metricBatcher:   metricBatcher,
 		metricCollector: metricCollector,
 		globalAttrs:     config.Attributes,
 		globalAttrsMux:  sync.RWMutex{},
 	}
+	if config.SelfTelemetry {
+		metricCollector.telemetry = instance.telemetryEvents
+	}
+	instance.levels.Store(instance.parseConfigLevels(config))
+	return instance
+}
+
+const (
+	instanceNew int32 = iota
+	instanceRunning
+	instanceStopped
+)
+
+// newExporter returns the exporter the pipelines write to
+// a noop config exports nothing, and a config without exporters uses those of the config file or the Vigilant exporter
+func newExporter(config *VigilantConfig) Exporter {
+	switch {
+	case config.Noop:
+		return NewMultiExporter()
+	case len(config.Exporters) == 0 && len(config.fileExporters) > 0:
+		return NewMultiExporter(newFileExporters(config)...)
+	case len(config.Exporters) == 1:
+		return config.Exporters[0]
+	case len(config.Exporters) > 1:
+		return NewMultiExporter(config.Exporters...)
+	default:
+		return NewVigilantExporter(config)
+	}
 }
 
-// start starts the Vigilant instance
+// start starts the Vigilant instance, it is a noop if the instance was already started or stopped
 func (a *instance) start() {
+	if !a.state.CompareAndSwap(instanceNew, instanceRunning) {
+		return
+	}
 	if a.noop {
 		return
 	}
+	startExporter(a.exporter)
 	a.logBatcher.start()
 	a.metricBatcher.start()
 	a.metricCollector.start()
 }
 
-// shutdown shuts down the Vigilant instance
-func (a *instance) shutdown() error {
-	a.logBatcher.stop()
-	a.metricBatcher.stop()
-	a.metricCollector.stop()
-	return nil
+// shutdown shuts down the Vigilant instance, only the first call has any effect
+// when the context is done before everything is sent, the remaining sends are cancelled
+// the returned error joins the failed sends of every pipeline
+func (a *instance) shutdown(ctx context.Context) error {
+	if a.state.Swap(instanceStopped) == instanceStopped {
+		return nil
+	}
+
+	// the pipelines are stopped concurrently so a slow one does not use up the context of the others
+	stops := []func(context.Context) error{a.logBatcher.stop, a.metricBatcher.stop, a.metricCollector.stop}
+	errs := make([]error, len(stops)+1)
+	var wg sync.WaitGroup
+	for i, stop := range stops {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = stop(ctx)
+		}()
+	}
+	wg.Wait()
+
+	errs[len(stops)] = a.exporter.Shutdown(ctx)
+	return errors.Join(errs...)
 }
 
-// captureLog captures a log message
-func (a *instance) captureLog(log *logMessage) {
-	if !isLevelEnabled(log.Level, a.level) {
-		return
+// flush sends the logs and metrics the instance holds without stopping it
+// the returned error joins the failed sends of every pipeline
+func (a *instance) flush(ctx context.Context) error {
+	if a.noop || a.state.Load() != instanceRunning {
+		return nil
 	}
+	return errors.Join(
+		a.logBatcher.flush(ctx),
+		a.metricBatcher.flush(ctx),
+		a.metricCollector.flush(ctx),
+	)
+}
+
+// droppedCounts returns the number of items each pipeline dropped because its queue was full
+func (a *instance) droppedCounts() DroppedCounts {
+	return DroppedCounts{
+		Logs:            a.logBatcher.logQueue.droppedCount(),
+		Metrics:         a.metricBatcher.metricQueue.droppedCount(),
+		CollectorEvents: a.metricCollector.droppedCount(),
+	}
+}
 
+// captureLog captures a log message, the level is expected to be checked by the caller with levelEnabled
+func (a *instance) captureLog(log *LogMessage) {
 	if log.Attributes != nil {
 		a.addGlobalAttributes(log.Attributes)
 	}
@@ -117,11 +263,12 @@ func (a *instance) captureLog(log *logMessage) {
 }
 
 // captureMetric captures a metric
-func (a *instance) captureMetric(metric *metricMessage) {
+func (a *instance) captureMetric(metric *MetricMessage) {
 	if a.noop {
 		return
 	}
 
+	a.addGlobalAttributes(metric.Attributes)
 	a.metricBatcher.addMetric(metric)
 }
 
@@ -131,6 +278,7 @@ func (a *instance) captureCounter(counter *counterEvent) {
 		return
 	}
 
+	a.addGlobalAttributes(counter.tags)
 	a.metricCollector.addCounter(counter)
 }
 
@@ -140,6 +288,7 @@ func (a *instance) captureGauge(gauge *gaugeEvent) {
 		return
 	}
 
+	a.addGlobalAttributes(gauge.tags)
 	a.metricCollector.addGauge(gauge)
 }
 
@@ -149,10 +298,12 @@ func (a *instance) captureHistogram(histogram *histogramEvent) {
 		return
 	}
 
+	a.addGlobalAttributes(histogram.tags)
 	a.metricCollector.addHistogram(histogram)
 }
 
-// addGlobalAttributes adds the global attributes to the given attributes
+// addGlobalAttributes adds the global attributes to the given log attributes or metric tags
+// the attributes set at the call site take precedence over the global attributes with the same key
 func (a *instance) addGlobalAttributes(attrs map[string]string) {
 	if attrs == nil {
 		return
@@ -160,5 +311,57 @@ func (a *instance) addGlobalAttributes(attrs map[string]string) {
 
 	a.globalAttrsMux.RLock()
 	defer a.globalAttrsMux.RUnlock()
-	maps.Copy(attrs, a.globalAttrs)
+	for key, value := range a.globalAttrs {
+		if _, ok := attrs[key]; !ok {
+			attrs[key] = value
+		}
+	}
+}
+
+// setGlobalAttribute sets a global attribute added to every log and metric
+// the map is copied first since it may be shared with the config
+func (a *instance) setGlobalAttribute(key string, value string) {
+	a.globalAttrsMux.Lock()
+	defer a.globalAttrsMux.Unlock()
+
+	attrs := maps.Clone(a.globalAttrs)
+	if attrs == nil {
+		attrs = make(map[string]string)
+	}
+	attrs[key] = value
+	a.globalAttrs = attrs
+}
+
+// replaceGlobalAttributes replaces the global attributes, keeping the service attribute unless attrs sets it
+func (a *instance) replaceGlobalAttributes(attrs map[string]string) {
+	a.globalAttrsMux.Lock()
+	defer a.globalAttrsMux.Unlock()
+
+	next := maps.Clone(attrs)
+	if next == nil {
+		next = make(map[string]string)
+	}
+	if service, ok := a.globalAttrs["service"]; ok {
+		if _, set := next["service"]; !set {
+			next["service"] = service
+		}
+	}
+	a.globalAttrs = next
+}
+
+// removeGlobalAttribute removes a global attribute
+func (a *instance) removeGlobalAttribute(key string) {
+	a.globalAttrsMux.Lock()
+	defer a.globalAttrsMux.Unlock()
+
+	attrs := maps.Clone(a.globalAttrs)
+	delete(attrs, key)
+	a.globalAttrs = attrs
+}
+
+// globalAttributes returns a copy of the global attributes
+func (a *instance) globalAttributes() map[string]string {
+	a.globalAttrsMux.RLock()
+	defer a.globalAttrsMux.RUnlock()
+	return maps.Clone(a.globalAttrs)
 }
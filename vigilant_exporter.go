@@ -0,0 +1,103 @@
+package vigilant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	logEndpoint    = "/api/message"
+	metricEndpoint = "/api/message"
+)
+
+// vigilantExporter is the Exporter that sends batches to the Vigilant server over HTTP
+type vigilantExporter struct {
+	token     string
+	transport *httpTransport
+}
+
+// NewVigilantExporter creates the Exporter that sends batches to the Vigilant server
+// it uses the token, endpoint, retry, compression and spool settings of the config,
+// it is the exporter used by default when the config has no exporters,
+// the spool is replayed once a client using the exporter starts, or after the first export
+func NewVigilantExporter(config *VigilantConfig) Exporter {
+	diag := newDiagnostics(config)
+	var batchSpool *spool
+	if config.Spool.Dir != "" {
+		var err error
+		batchSpool, err = newSpool(config.Spool, diag)
+		if err != nil {
+			diag.reportError(fmt.Errorf("error opening spool, continuing without it: %w", err))
+		}
+	}
+	transport := newHTTPTransport(
+		config.Token,
+		getEndpoint(config),
+		&http.Client{},
+		config.Retry,
+		config.Compression,
+		nil,
+		batchSpool,
+		diag,
+	)
+
+	return &vigilantExporter{
+		token:     config.Token,
+		transport: transport,
+	}
+}
+
+// ExportLogs sends the logs to the server
+func (e *vigilantExporter) ExportLogs(ctx context.Context, logs []*LogMessage) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	batch := &messageBatch{
+		Token: e.token,
+		Logs:  logs,
+	}
+
+	return e.sendBatch(ctx, logEndpoint, batch)
+}
+
+// ExportMetrics sends the metrics to the server
+func (e *vigilantExporter) ExportMetrics(ctx context.Context, metrics *MetricBatch) error {
+	if metrics == nil || metrics.empty() {
+		return nil
+	}
+
+	batch := &messageBatch{
+		Token:             e.token,
+		Metrics:           metrics.Metrics,
+		MetricsCounters:   metrics.Counters,
+		MetricsGauges:     metrics.Gauges,
+		MetricsHistograms: metrics.Histograms,
+	}
+
+	return e.sendBatch(ctx, metricEndpoint, batch)
+}
+
+// start starts replaying the spool, it is called when a client using the exporter starts
+func (e *vigilantExporter) start() {
+	e.transport.start()
+}
+
+// Shutdown stops replaying the spool, batches that were not replayed stay on disk for the next start
+// it can be called more than once
+func (e *vigilantExporter) Shutdown(ctx context.Context) error {
+	e.transport.stop()
+	return nil
+}
+
+// sendBatch marshals the batch and sends it to the given path
+func (e *vigilantExporter) sendBatch(ctx context.Context, path string, batch *messageBatch) error {
+	batchBytes, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	return e.transport.send(ctx, path, batchBytes)
+}
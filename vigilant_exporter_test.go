@@ -0,0 +1,53 @@
+package vigilant
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestVigilantExporterSharedByTwoClients(t *testing.T) {
+	server := newTestServer(t, nil)
+	config := testConfig(server).WithSpool(SpoolConfig{Dir: t.TempDir()}).Build()
+	exporter := NewVigilantExporter(config)
+
+	first := NewClient(testConfig(server).WithExporters(exporter).Build())
+	second := NewClient(testConfig(server).WithExporters(exporter).Build())
+	first.LogInfo("first")
+	second.LogInfo("second")
+
+	if err := first.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if count := server.logCount(); count != 2 {
+		t.Fatalf("expected 2 logs, got %d", count)
+	}
+}
+
+func TestVigilantExporterStartsWithClient(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(SpoolConfig{Dir: dir}, defaultDiagnostics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.write(logEndpoint, []byte(`{"token":"tk_test","logs":[{"body":"spooled"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer(t, nil)
+	exporter := NewVigilantExporter(testConfig(server).WithSpool(SpoolConfig{Dir: dir}).Build())
+	time.Sleep(50 * time.Millisecond)
+	if count := server.requestCount(); count != 0 {
+		t.Fatalf("expected the exporter to wait for a client before replaying, got %d requests", count)
+	}
+
+	client := NewClient(testConfig(server).WithExporters(exporter).Build())
+	defer client.Shutdown()
+	waitFor(t, 5*time.Second, func() bool { return server.logCount() == 1 })
+}
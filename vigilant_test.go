@@ -0,0 +1,196 @@
+package vigilant
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// produce captures logs and metrics through the client until stop is closed
+func produce(client *Client, stop <-chan struct{}) {
+	logger := client.Named("db").With(String("shard", "1"))
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		client.LogInfo("info")
+		client.LogWarnw("warn", "attempt", i)
+		logger.LogDebug("debug")
+		client.MetricEvent("events", 1, Tag("kind", "event"))
+		client.MetricCounter("requests", 1)
+		client.MetricGauge("connections", 1, GaugeModeInc)
+		client.MetricHistogram("latency", float64(i%100))
+		if i%50 == 0 {
+			client.AddGlobalAttribute(String("round", "a"))
+			client.SetLevel(LEVEL_DEBUG)
+		}
+	}
+}
+
+// producePackage captures logs and metrics through the package-level functions until stop is closed
+func producePackage(stop <-chan struct{}) {
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		LogInfo("info")
+		LogErrorw("error", "attempt", i)
+		Named("http").LogWarn("warn")
+		MetricEvent("events", 1)
+		MetricCounter("requests", 1)
+		MetricGauge("connections", 1, GaugeModeSet)
+		MetricHistogram("latency", float64(i%100))
+		if i%50 == 0 {
+			AddGlobalAttribute(String("round", "b"))
+			Flush(context.Background())
+		}
+	}
+}
+
+func TestConcurrentCaptureDuringShutdown(t *testing.T) {
+	server := newTestServer(t, nil)
+	for _, shutdown := range []func(c *Client) error{
+		func(c *Client) error { return c.Shutdown() },
+		func(c *Client) error {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+			defer cancel()
+			c.ShutdownContext(ctx)
+			return nil
+		},
+	} {
+		// small queues with different policies keep the shutdown short while the producers race it
+		client := NewClient(testConfig(server).
+			WithLogQueue(QueueConfig{Capacity: 16, Policy: OverflowDropOldest}).
+			WithMetricQueue(QueueConfig{Capacity: 16, Policy: OverflowBlockTimeout, Timeout: time.Millisecond}).
+			WithCollectorQueue(QueueConfig{Capacity: 16, Policy: OverflowBlock}).
+			WithBatch(BatchConfig{MaxSize: 8, Interval: time.Millisecond}).
+			Build())
+
+		stop := make(chan struct{})
+		var producers sync.WaitGroup
+		for range 8 {
+			producers.Add(1)
+			go func() {
+				defer producers.Done()
+				produce(client, stop)
+			}()
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		var shutdowns sync.WaitGroup
+		var failed atomic.Int32
+		for range 3 {
+			shutdowns.Add(1)
+			go func() {
+				defer shutdowns.Done()
+				if err := shutdown(client); err != nil {
+					failed.Add(1)
+				}
+			}()
+		}
+		shutdowns.Wait()
+
+		// capturing after the shutdown is a noop
+		time.Sleep(5 * time.Millisecond)
+		close(stop)
+		producers.Wait()
+		if failed.Load() != 0 {
+			t.Fatalf("expected the shutdowns to succeed, %d failed", failed.Load())
+		}
+	}
+}
+
+func TestConcurrentPackageCaptureAcrossInitAndShutdown(t *testing.T) {
+	resetPackageSettings(t)
+	SetInternalLogger(discardLogger{})
+	server := newTestServer(t, nil)
+
+	stop := make(chan struct{})
+	var producers sync.WaitGroup
+	for range 8 {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			producePackage(stop)
+		}()
+	}
+
+	for round := range 5 {
+		config := testConfig(server).
+			WithLogQueue(QueueConfig{Capacity: 16, Policy: OverflowDropNewest}).
+			WithMetricQueue(QueueConfig{Capacity: 16, Policy: OverflowDropNewest}).
+			WithBatch(BatchConfig{MaxSize: 8, Interval: time.Millisecond}).
+			Build()
+		if err := InitE(config); err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		var shutdowns sync.WaitGroup
+		for range 2 {
+			shutdowns.Add(1)
+			go func() {
+				defer shutdowns.Done()
+				ShutdownContext(ctx)
+			}()
+		}
+		shutdowns.Wait()
+		cancel()
+	}
+
+	close(stop)
+	producers.Wait()
+	if server.logCount() == 0 {
+		t.Fatal("expected logs to be sent")
+	}
+}
+
+func TestCallSiteAttributesTakePrecedenceOverGlobals(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := NewClient(testConfig(server).WithAttributes(String("env", "prod"), String("region", "eu")).Build())
+
+	client.LogInfot("log", String("env", "dev"))
+	client.MetricEvent("event", 1, Tag("env", "dev"))
+	client.MetricCounter("counter", 1, Tag("env", "dev"))
+	client.MetricGauge("gauge", 1, GaugeModeSet, Tag("env", "dev"))
+	client.MetricHistogram("histogram", 1, Tag("env", "dev"))
+	if err := client.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mux.Lock()
+	defer server.mux.Unlock()
+	var captured []map[string]string
+	for _, batch := range server.batches {
+		for _, log := range batch.Logs {
+			captured = append(captured, log.Attributes)
+		}
+		for _, metric := range batch.Metrics {
+			captured = append(captured, metric.Attributes)
+		}
+		for _, counter := range batch.MetricsCounters {
+			captured = append(captured, counter.Tags)
+		}
+		for _, gauge := range batch.MetricsGauges {
+			captured = append(captured, gauge.Tags)
+		}
+		for _, histogram := range batch.MetricsHistograms {
+			captured = append(captured, histogram.Tags)
+		}
+	}
+	if len(captured) != 5 {
+		t.Fatalf("expected 5 captured items, got %d", len(captured))
+	}
+	for _, attrs := range captured {
+		if attrs["env"] != "dev" || attrs["region"] != "eu" {
+			t.Errorf("expected env=dev from the call site and region=eu from the globals, got %v", attrs)
+		}
+	}
+}